@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +16,37 @@ type Config struct {
 	SessionSecret string
 	Port          string
 	Environment   string
+
+	// GenreLanguages are the TMDB languages genres are synced in. The first
+	// one is used when resolving names for display.
+	GenreLanguages []string
+	// WebhookAllowPrivate lets webhooks reach private, loopback and other
+	// internal addresses, e.g. for receivers on the same network.
+	WebhookAllowPrivate bool
+
+	// Background jobs
+	GenreSyncInterval       time.Duration
+	MetadataRefreshInterval time.Duration
+	MetadataRefreshBatch    int
+	MetadataMaxAge          time.Duration
+	MetadataRequestDelay    time.Duration
+	TrashRetention          time.Duration
+	TrashPurgeInterval      time.Duration
+	SimilarityInterval      time.Duration
+	SimilarityNeighbors     int
+	SimilarityMinOverlap    int
+	ShowRefreshInterval     time.Duration
+	ShowRefreshBatch        int
+	ShowMaxAge              time.Duration
+	ProviderRefreshInterval time.Duration
+	ProviderRefreshBatch    int
+	ProviderMaxAge          time.Duration
+	ReleaseRefreshInterval  time.Duration
+	ReleaseRefreshBatch     int
+	ReleaseMaxAge           time.Duration
+	ReleaseNotifyInterval   time.Duration
+	DigestInterval          time.Duration
+	WebhookRetryInterval    time.Duration
 }
 
 func LoadConfig() *Config {
@@ -27,6 +61,32 @@ func LoadConfig() *Config {
 		SessionSecret: getEnv("SESSION_SECRET", "your-secret-key-change-in-production"),
 		Port:          getEnv("PORT", "8080"),
 		Environment:   getEnv("ENVIRONMENT", "development"),
+
+		GenreLanguages:      getListEnv("GENRE_LANGUAGES", []string{"es-MX", "en-US"}),
+		WebhookAllowPrivate: getBoolEnv("WEBHOOK_ALLOW_PRIVATE", false),
+
+		GenreSyncInterval:       getDurationEnv("GENRE_SYNC_INTERVAL", 24*time.Hour),
+		MetadataRefreshInterval: getDurationEnv("METADATA_REFRESH_INTERVAL", time.Hour),
+		MetadataRefreshBatch:    getIntEnv("METADATA_REFRESH_BATCH", 50),
+		MetadataMaxAge:          getDurationEnv("METADATA_MAX_AGE", 7*24*time.Hour),
+		MetadataRequestDelay:    getDurationEnv("METADATA_REQUEST_DELAY", 250*time.Millisecond),
+		TrashRetention:          time.Duration(getIntEnv("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		TrashPurgeInterval:      getDurationEnv("TRASH_PURGE_INTERVAL", 24*time.Hour),
+		SimilarityInterval:      getDurationEnv("SIMILARITY_INTERVAL", 6*time.Hour),
+		SimilarityNeighbors:     getIntEnv("SIMILARITY_NEIGHBORS", 10),
+		SimilarityMinOverlap:    getIntEnv("SIMILARITY_MIN_OVERLAP", 5),
+		ShowRefreshInterval:     getDurationEnv("SHOW_REFRESH_INTERVAL", time.Hour),
+		ShowRefreshBatch:        getIntEnv("SHOW_REFRESH_BATCH", 20),
+		ShowMaxAge:              getDurationEnv("SHOW_MAX_AGE", 24*time.Hour),
+		ProviderRefreshInterval: getDurationEnv("PROVIDER_REFRESH_INTERVAL", time.Hour),
+		ProviderRefreshBatch:    getIntEnv("PROVIDER_REFRESH_BATCH", 50),
+		ProviderMaxAge:          getDurationEnv("PROVIDER_MAX_AGE", 24*time.Hour),
+		ReleaseRefreshInterval:  getDurationEnv("RELEASE_REFRESH_INTERVAL", time.Hour),
+		ReleaseRefreshBatch:     getIntEnv("RELEASE_REFRESH_BATCH", 50),
+		ReleaseMaxAge:           getDurationEnv("RELEASE_MAX_AGE", 72*time.Hour),
+		ReleaseNotifyInterval:   getDurationEnv("RELEASE_NOTIFY_INTERVAL", 6*time.Hour),
+		DigestInterval:          getDurationEnv("DIGEST_INTERVAL", time.Hour),
+		WebhookRetryInterval:    getDurationEnv("WEBHOOK_RETRY_INTERVAL", 30*time.Second),
 	}
 
 	// Validate required environment variables
@@ -46,4 +106,59 @@ func getEnv(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration for %s: %q, using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+func getIntEnv(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid integer for %s: %q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid boolean for %s: %q, using %t", key, value, defaultValue)
+		return defaultValue
+	}
+	return b
+}
+
+func getListEnv(key string, defaultValue []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
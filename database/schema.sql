@@ -7,6 +7,21 @@ CREATE TABLE IF NOT EXISTS users (
     username VARCHAR(50) UNIQUE NOT NULL,
     email VARCHAR(255) UNIQUE NOT NULL,
     password_hash VARCHAR(255) NOT NULL,
+    taste_matching_opt_out BOOLEAN NOT NULL DEFAULT FALSE,
+    region VARCHAR(2),
+    streaming_services JSONB,
+    language VARCHAR(5),
+    calendar_token VARCHAR(64) UNIQUE,
+    notification_webhook VARCHAR(255),
+    weekly_digest BOOLEAN NOT NULL DEFAULT FALSE,
+    digest_sent_at TIMESTAMP,
+    private_profile BOOLEAN NOT NULL DEFAULT FALSE,
+    feed_hide_added BOOLEAN NOT NULL DEFAULT FALSE,
+    feed_hide_watched BOOLEAN NOT NULL DEFAULT FALSE,
+    feed_hide_ratings BOOLEAN NOT NULL DEFAULT FALSE,
+    public_page BOOLEAN NOT NULL DEFAULT FALSE,
+    public_ratings BOOLEAN NOT NULL DEFAULT FALSE,
+    allow_indexing BOOLEAN NOT NULL DEFAULT FALSE,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP
@@ -22,28 +37,385 @@ CREATE TABLE IF NOT EXISTS favorite_movies (
     release_date DATE,
     poster_path VARCHAR(255),
     genre_ids JSONB,
-    status VARCHAR(20) DEFAULT 'por_ver' CHECK (status IN ('por_ver', 'vista', 'recomendada')),
+    original_language VARCHAR(10),
+    runtime INTEGER,
+    original_title VARCHAR(255),
+    imdb_id VARCHAR(20),
+    backdrop_path VARCHAR(255),
+    vote_average DOUBLE PRECISION,
+    metadata_synced_at TIMESTAMP,
+    metadata_tried_at TIMESTAMP,
+    status VARCHAR(20) DEFAULT 'por_ver',
+    position DOUBLE PRECISION NOT NULL DEFAULT 0,
+    priority INTEGER NOT NULL DEFAULT 0,
+    snoozed_until TIMESTAMP,
     rating INTEGER CHECK (rating >= 1 AND rating <= 10),
     notes TEXT,
     recommended_by VARCHAR(100),
+    recommender_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
     added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     watched_at TIMESTAMP,
+    progress_minutes INTEGER CHECK (progress_minutes >= 0),
+    abandon_reason TEXT,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP,
     UNIQUE(user_id, tmdb_id)
 );
 
+-- Databases created from the original schema only allowed the first three
+-- statuses
+ALTER TABLE favorite_movies DROP CONSTRAINT IF EXISTS favorite_movies_status_check;
+
+-- Accent-insensitive full-text search over favorite_movies
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text AS
+    $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT;
+
+ALTER TABLE favorite_movies ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', immutable_unaccent(coalesce(title, ''))), 'A') ||
+        setweight(to_tsvector('simple', immutable_unaccent(coalesce(original_title, ''))), 'A') ||
+        setweight(to_tsvector('simple', immutable_unaccent(coalesce(notes, ''))), 'B') ||
+        setweight(to_tsvector('simple', immutable_unaccent(coalesce(recommended_by, ''))), 'B') ||
+        setweight(to_tsvector('simple', immutable_unaccent(coalesce(overview, ''))), 'C')
+    ) STORED;
+
+-- Watch goals table
+CREATE TABLE IF NOT EXISTS goals (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL,
+    target INTEGER NOT NULL CHECK (target > 0),
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    genre_id INTEGER,
+    release_year_from INTEGER,
+    release_year_to INTEGER,
+    original_language VARCHAR(10),
+    min_runtime INTEGER,
+    max_runtime INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+-- Genre catalogue synced from TMDB, one row per genre and language
+CREATE TABLE IF NOT EXISTS genres (
+    genre_id INTEGER NOT NULL,
+    language VARCHAR(10) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (genre_id, language)
+);
+
+-- Per-user tags and lists
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS movie_lists (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL,
+    description TEXT,
+    visibility VARCHAR(10) NOT NULL DEFAULT 'private',
+    share_token VARCHAR(64) UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, slug)
+);
+
+CREATE TABLE IF NOT EXISTS favorite_movie_tags (
+    favorite_movie_id INTEGER REFERENCES favorite_movies(id) ON DELETE CASCADE,
+    tag_id INTEGER REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (favorite_movie_id, tag_id)
+);
+
+CREATE TABLE IF NOT EXISTS favorite_movie_lists (
+    favorite_movie_id INTEGER REFERENCES favorite_movies(id) ON DELETE CASCADE,
+    movie_list_id INTEGER REFERENCES movie_lists(id) ON DELETE CASCADE,
+    PRIMARY KEY (favorite_movie_id, movie_list_id)
+);
+
+-- Append-only change history of favorite movies
+CREATE TABLE IF NOT EXISTS favorite_history (
+    id SERIAL PRIMARY KEY,
+    favorite_movie_id INTEGER NOT NULL REFERENCES favorite_movies(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    field VARCHAR(50) NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    source VARCHAR(10) NOT NULL CHECK (source IN ('web', 'api', 'import')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Each user's most similar users by rating, recomputed in batch
+CREATE TABLE IF NOT EXISTS user_similarities (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    neighbor_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    similarity DOUBLE PRECISION NOT NULL,
+    overlap INTEGER NOT NULL,
+    computed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, neighbor_id)
+);
+
+-- Directors of tracked TMDB movies, filled by the metadata refresh job
+CREATE TABLE IF NOT EXISTS movie_directors (
+    tmdb_id INTEGER NOT NULL,
+    person_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    profile_path VARCHAR(255),
+    PRIMARY KEY (tmdb_id, person_id)
+);
+
+-- TV series tracked by users, with TMDB's details copied in
+CREATE TABLE IF NOT EXISTS tracked_shows (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tmdb_id INTEGER NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    original_name VARCHAR(255),
+    overview TEXT,
+    first_air_date DATE,
+    poster_path VARCHAR(255),
+    backdrop_path VARCHAR(255),
+    genre_ids JSONB,
+    original_language VARCHAR(10),
+    show_status VARCHAR(50),
+    episode_run_time INTEGER,
+    vote_average DOUBLE PRECISION,
+    status VARCHAR(20) DEFAULT 'por_ver',
+    synced_at TIMESTAMP,
+    tried_at TIMESTAMP,
+    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, tmdb_id)
+);
+
+-- Episodes of tracked shows and when the user watched them
+CREATE TABLE IF NOT EXISTS tracked_episodes (
+    id SERIAL PRIMARY KEY,
+    tracked_show_id INTEGER NOT NULL REFERENCES tracked_shows(id) ON DELETE CASCADE,
+    season_number INTEGER NOT NULL,
+    episode_number INTEGER NOT NULL,
+    tmdb_id INTEGER,
+    name VARCHAR(255),
+    overview TEXT,
+    air_date DATE,
+    runtime INTEGER,
+    still_path VARCHAR(255),
+    watched_at TIMESTAMP,
+    UNIQUE(tracked_show_id, season_number, episode_number)
+);
+
+-- Where watchlist movies can be watched, per region, cached from TMDB
+CREATE TABLE IF NOT EXISTS movie_providers (
+    tmdb_id INTEGER NOT NULL,
+    region VARCHAR(2) NOT NULL,
+    provider_id INTEGER NOT NULL,
+    type VARCHAR(10) NOT NULL,
+    provider_name VARCHAR(100) NOT NULL,
+    logo_path VARCHAR(255),
+    display_priority INTEGER,
+    PRIMARY KEY (tmdb_id, region, provider_id, type)
+);
+
+-- When each movie's providers were last fetched for a region
+CREATE TABLE IF NOT EXISTS movie_provider_syncs (
+    tmdb_id INTEGER NOT NULL,
+    region VARCHAR(2) NOT NULL,
+    synced_at TIMESTAMP,
+    tried_at TIMESTAMP,
+    PRIMARY KEY (tmdb_id, region)
+);
+
+-- Databases created from the original schema required synced_at
+ALTER TABLE movie_provider_syncs ALTER COLUMN synced_at DROP NOT NULL;
+
+-- Release dates of watchlist movies, per region, cached from TMDB
+CREATE TABLE IF NOT EXISTS movie_release_dates (
+    tmdb_id INTEGER NOT NULL,
+    region VARCHAR(2) NOT NULL,
+    type INTEGER NOT NULL,
+    date DATE NOT NULL,
+    note TEXT,
+    PRIMARY KEY (tmdb_id, region, type, date)
+);
+
+-- When each movie's release dates were last fetched for a region
+CREATE TABLE IF NOT EXISTS movie_release_syncs (
+    tmdb_id INTEGER NOT NULL,
+    region VARCHAR(2) NOT NULL,
+    synced_at TIMESTAMP,
+    tried_at TIMESTAMP,
+    PRIMARY KEY (tmdb_id, region)
+);
+
+-- Databases created from the original schema required synced_at
+ALTER TABLE movie_release_syncs ALTER COLUMN synced_at DROP NOT NULL;
+
+-- Notifications sent to users; only in-app ones show up in the inbox
+CREATE TABLE IF NOT EXISTS notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    key VARCHAR(100) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT,
+    url VARCHAR(255),
+    in_app BOOLEAN NOT NULL DEFAULT FALSE,
+    read_at TIMESTAMP,
+    digested_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, key)
+);
+
+-- Channels users enabled or disabled per notification type
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    channel VARCHAR(10) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, type, channel)
+);
+
+-- URLs users registered to receive their favorites events
+CREATE TABLE IF NOT EXISTS webhooks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url VARCHAR(255) NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Events sent, or being retried, to webhooks
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id SERIAL PRIMARY KEY,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    status_code INTEGER,
+    error TEXT,
+    response_body TEXT,
+    duration_ms INTEGER,
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Who follows whom; requests to private profiles stay pending until accepted
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    followee_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMP,
+    PRIMARY KEY (follower_id, followee_id)
+);
+
+-- What users did to their favorites, shown in their followers' feeds
+CREATE TABLE IF NOT EXISTS activities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    favorite_movie_id INTEGER NOT NULL REFERENCES favorite_movies(id) ON DELETE CASCADE,
+    type VARCHAR(10) NOT NULL,
+    rating INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Movies users recommended to each other
+CREATE TABLE IF NOT EXISTS movie_recommendations (
+    id SERIAL PRIMARY KEY,
+    sender_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tmdb_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    poster_path VARCHAR(255),
+    message TEXT,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    favorite_movie_id INTEGER REFERENCES favorite_movies(id) ON DELETE SET NULL,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Indexes for better performance
 CREATE INDEX IF NOT EXISTS idx_favorite_movies_user_id ON favorite_movies(user_id);
 CREATE INDEX IF NOT EXISTS idx_favorite_movies_status ON favorite_movies(user_id, status);
+CREATE INDEX IF NOT EXISTS idx_favorite_movies_watchlist ON favorite_movies(user_id, status, position);
 CREATE INDEX IF NOT EXISTS idx_favorite_movies_added_at ON favorite_movies(user_id, added_at DESC);
+CREATE INDEX IF NOT EXISTS idx_favorite_movies_metadata_synced_at ON favorite_movies(metadata_synced_at);
+CREATE INDEX IF NOT EXISTS idx_favorite_movies_search ON favorite_movies USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_favorite_history_favorite_movie_id ON favorite_history(favorite_movie_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_goals_user_id ON goals(user_id);
 CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
 CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_movie_directors_person_id ON movie_directors(person_id);
+CREATE INDEX IF NOT EXISTS idx_tracked_shows_synced_at ON tracked_shows(synced_at);
+CREATE INDEX IF NOT EXISTS idx_movie_providers_provider_id ON movie_providers(provider_id);
+CREATE INDEX IF NOT EXISTS idx_movie_provider_syncs_synced_at ON movie_provider_syncs(synced_at);
+CREATE INDEX IF NOT EXISTS idx_movie_release_syncs_synced_at ON movie_release_syncs(synced_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications(created_at);
+CREATE INDEX IF NOT EXISTS idx_follows_followee_id ON follows(followee_id);
+CREATE INDEX IF NOT EXISTS idx_activities_user_created ON activities(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_activities_favorite_movie_id ON activities(favorite_movie_id);
+CREATE INDEX IF NOT EXISTS idx_favorite_movies_recommender_id ON favorite_movies(recommender_id);
+CREATE INDEX IF NOT EXISTS idx_movie_recommendations_sender_id ON movie_recommendations(sender_id);
+CREATE INDEX IF NOT EXISTS idx_movie_recommendations_recipient ON movie_recommendations(recipient_id, status);
+CREATE INDEX IF NOT EXISTS idx_movie_recommendations_favorite_movie_id ON movie_recommendations(favorite_movie_id);
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks(user_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries(next_attempt_at);
 
 -- Comments for documentation
 COMMENT ON TABLE users IS 'Application users with authentication credentials';
 COMMENT ON TABLE favorite_movies IS 'User favorite movies with personal metadata';
-COMMENT ON COLUMN favorite_movies.status IS 'Movie status: por_ver (to watch), vista (watched), recomendada (recommended)';
+COMMENT ON COLUMN favorite_movies.status IS 'Movie status: por_ver (to watch), viendo (watching), vista (watched), recomendada (recommended), abandonada (abandoned). Valid values and transitions are defined in models/status.go';
+COMMENT ON COLUMN favorite_movies.position IS 'Watchlist order (lowest first). Fractional so a move only rewrites the moved row';
+COMMENT ON COLUMN favorite_movies.priority IS 'Priority flag: 0 normal, 1 high, 2 must watch';
+COMMENT ON COLUMN favorite_movies.snoozed_until IS 'Set by "not tonight" in the picker; the movie is not picked again before then';
 COMMENT ON COLUMN favorite_movies.rating IS 'Personal rating from 1-10 stars';
-COMMENT ON COLUMN favorite_movies.tmdb_id IS 'The Movie Database ID for external API reference';
\ No newline at end of file
+COMMENT ON COLUMN tracked_shows.status IS 'Derived from the episodes: por_ver until one is watched, vista when every aired episode is, viendo otherwise';
+COMMENT ON COLUMN users.region IS 'ISO 3166-1 country used for watch providers, e.g. MX';
+COMMENT ON COLUMN users.streaming_services IS 'TMDB provider IDs of the streaming services the user subscribes to';
+COMMENT ON COLUMN users.language IS 'UI and TMDB content language (en, es); empty follows the browser';
+COMMENT ON COLUMN users.private_profile IS 'Follow requests need the user''s approval; otherwise follows are accepted right away';
+COMMENT ON COLUMN users.feed_hide_added IS 'Keep movies the user adds out of followers'' feeds; feed_hide_watched and feed_hide_ratings likewise';
+COMMENT ON COLUMN follows.status IS 'pending (waiting for a private profile to accept) or accepted';
+COMMENT ON COLUMN favorite_movies.recommender_id IS 'The user recommended_by names, for movies added by accepting an in-app recommendation';
+COMMENT ON COLUMN movie_recommendations.status IS 'pending, accepted (added to the recipient''s favorites as favorite_movie_id) or declined';
+COMMENT ON COLUMN activities.type IS 'added, watched or rated';
+COMMENT ON COLUMN users.public_page IS 'Anyone can see /u/<username> with the user''s public lists; public_ratings adds their ratings';
+COMMENT ON COLUMN users.allow_indexing IS 'Search engines may index the user''s public pages; unlisted lists are never indexed';
+COMMENT ON COLUMN movie_lists.visibility IS 'private, unlisted (anyone with the share link) or public (also on the owner''s public page)';
+COMMENT ON COLUMN movie_lists.share_token IS 'Secret in the share link of unlisted and public lists';
+COMMENT ON COLUMN users.calendar_token IS 'Secret in the URL of the private release calendar feed';
+COMMENT ON COLUMN notifications.key IS 'What the notification is about, e.g. release:603:MX:3:2026-10-30; a user is never notified twice of the same key';
+COMMENT ON COLUMN webhooks.secret IS 'Key of the HMAC-SHA256 signature sent in the X-Movie-Tracker-Signature header';
+COMMENT ON COLUMN webhook_deliveries.status IS 'pending (waiting for its first attempt or a retry), delivered or failed';
+COMMENT ON COLUMN webhook_deliveries.next_attempt_at IS 'When a pending delivery is retried; retries back off exponentially';
+COMMENT ON COLUMN notification_preferences.channel IS 'in_app, email or webhook; without a row the type''s default channels apply';
+COMMENT ON COLUMN movie_release_dates.type IS 'TMDB release type: 1 premiere, 2 limited theatrical, 3 theatrical, 4 digital, 5 physical, 6 TV';
+COMMENT ON COLUMN movie_providers.type IS 'How the provider offers the movie: flatrate, free, ads, rent or buy';
+COMMENT ON COLUMN tracked_shows.show_status IS 'TMDB status such as Returning Series or Ended; ended shows are no longer refreshed';
+COMMENT ON COLUMN movie_provider_syncs.synced_at IS 'Last time the providers were fetched; NULL while every fetch failed';
+COMMENT ON COLUMN movie_provider_syncs.tried_at IS 'Last time a refresh was tried, failed ones included, so failing movies go to the back of the queue';
+COMMENT ON COLUMN movie_release_syncs.synced_at IS 'Last time the release dates were fetched; NULL while every fetch failed';
+COMMENT ON COLUMN movie_release_syncs.tried_at IS 'Last time a refresh was tried, failed ones included, so failing movies go to the back of the queue';
+COMMENT ON COLUMN tracked_shows.tried_at IS 'Last time a refresh was tried, failed ones included, so failing shows go to the back of the queue';
+COMMENT ON TABLE goals IS 'Watch goals evaluated against watched favorite movies';
+COMMENT ON TABLE genres IS 'TMDB movie genre names per language, refreshed periodically';
+COMMENT ON COLUMN favorite_movies.tmdb_id IS 'The Movie Database ID for external API reference';
+COMMENT ON COLUMN favorite_movies.metadata_synced_at IS 'Last time the TMDB snapshot (title, poster, runtime...) was refreshed';
+COMMENT ON COLUMN favorite_movies.metadata_tried_at IS 'Last time a refresh was tried, failed ones included, so failing movies go to the back of the queue';
\ No newline at end of file
@@ -0,0 +1,33 @@
+package database
+
+import "gorm.io/gorm"
+
+// SetupFullTextSearch adds the accent-insensitive search_vector column and its
+// GIN index to favorite_movies. AutoMigrate can't express generated columns,
+// so this runs as raw SQL after it. Every statement is idempotent.
+func SetupFullTextSearch(db *gorm.DB) error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS unaccent`,
+		// unaccent() is only STABLE, which generated columns and indexes reject
+		`CREATE OR REPLACE FUNCTION immutable_unaccent(text) RETURNS text AS
+			$$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$
+			LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT`,
+		`ALTER TABLE favorite_movies ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				setweight(to_tsvector('simple', immutable_unaccent(coalesce(title, ''))), 'A') ||
+				setweight(to_tsvector('simple', immutable_unaccent(coalesce(original_title, ''))), 'A') ||
+				setweight(to_tsvector('simple', immutable_unaccent(coalesce(notes, ''))), 'B') ||
+				setweight(to_tsvector('simple', immutable_unaccent(coalesce(recommended_by, ''))), 'B') ||
+				setweight(to_tsvector('simple', immutable_unaccent(coalesce(overview, ''))), 'C')
+			) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_favorite_movies_search ON favorite_movies USING GIN (search_vector)`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,11 @@
+package database
+
+import "gorm.io/gorm"
+
+// DropLegacyStatusCheck drops the CHECK constraint the original schema put on
+// favorite_movies.status, which only allowed por_ver, vista and recomendada
+// and so rejects every status added since. AutoMigrate never drops
+// constraints, so this runs after it. It is idempotent.
+func DropLegacyStatusCheck(db *gorm.DB) error {
+	return db.Exec(`ALTER TABLE favorite_movies DROP CONSTRAINT IF EXISTS favorite_movies_status_check`).Error
+}
@@ -0,0 +1,16 @@
+package database
+
+import "gorm.io/gorm"
+
+// AllowUnsyncedProviders drops the NOT NULL the original schema put on
+// movie_provider_syncs.synced_at, so a pair whose fetch failed can record the
+// try without counting as synced. AutoMigrate never makes a column nullable,
+// so this runs after it. It is idempotent.
+func AllowUnsyncedProviders(db *gorm.DB) error {
+	return db.Exec(`ALTER TABLE movie_provider_syncs ALTER COLUMN synced_at DROP NOT NULL`).Error
+}
+
+// AllowUnsyncedReleases does the same for movie_release_syncs.synced_at.
+func AllowUnsyncedReleases(db *gorm.DB) error {
+	return db.Exec(`ALTER TABLE movie_release_syncs ALTER COLUMN synced_at DROP NOT NULL`).Error
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"movie-tracker/i18n"
 	"movie-tracker/models"
 	"movie-tracker/services"
 	"net/http"
@@ -10,23 +11,27 @@ import (
 )
 
 type AuthHandler struct {
-	authService *services.AuthService
+	authService   *services.AuthService
+	genreService  *services.GenreService
+	pickerService *services.PickerService
 }
 
 func NewAuthHandler() *AuthHandler {
 	return &AuthHandler{
-		authService: services.NewAuthService(),
+		authService:   services.NewAuthService(),
+		genreService:  services.NewGenreService(),
+		pickerService: services.NewPickerService(),
 	}
 }
 
 func (h *AuthHandler) ShowLogin(c *gin.Context) {
-	c.HTML(http.StatusOK, "login.html", gin.H{
+	render(c, http.StatusOK, "login.html", gin.H{
 		"title": "Login",
 	})
 }
 
 func (h *AuthHandler) ShowRegister(c *gin.Context) {
-	c.HTML(http.StatusOK, "register.html", gin.H{
+	render(c, http.StatusOK, "register.html", gin.H{
 		"title": "Register",
 	})
 }
@@ -37,9 +42,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	user, err := h.authService.Login(username, password)
 	if err != nil {
-		c.HTML(http.StatusBadRequest, "login.html", gin.H{
-			"title": "Login",
-			"error": err.Error(),
+		render(c, http.StatusBadRequest, "login.html", gin.H{
+			"title":    "Login",
+			"error":    err.Error(),
 			"username": username,
 		})
 		return
@@ -57,13 +62,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	email := c.PostForm("email")
 	password := c.PostForm("password")
 
-	user, err := h.authService.Register(username, email, password)
+	user, err := h.authService.Register(username, email, password, requestLanguage(c))
 	if err != nil {
-		c.HTML(http.StatusBadRequest, "register.html", gin.H{
-			"title": "Register",
-			"error": err.Error(),
+		render(c, http.StatusBadRequest, "register.html", gin.H{
+			"title":    "Register",
+			"error":    err.Error(),
 			"username": username,
-			"email": email,
+			"email":    email,
 		})
 		return
 	}
@@ -87,8 +92,12 @@ func (h *AuthHandler) Dashboard(c *gin.Context) {
 	user, _ := c.Get("user")
 	userModel := user.(*models.User)
 
-	c.HTML(http.StatusOK, "dashboard.html", gin.H{
-		"title": "Dashboard",
-		"user":  userModel,
+	pickerOptions, _ := h.pickerService.GetOptions(userModel.ID)
+
+	render(c, http.StatusOK, "dashboard.html", gin.H{
+		"title":  "Dashboard",
+		"user":   userModel,
+		"genres": h.genreService.WithLanguage(i18n.TMDBLocale(requestLanguage(c))).GetGenres(),
+		"picker": pickerOptions,
 	})
-}
\ No newline at end of file
+}
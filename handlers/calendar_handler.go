@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"movie-tracker/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// calendarMonths is how many months the calendar page shows at most.
+	calendarMonths = 12
+	// calendarFeedDays is how many days of past releases the feed keeps, so
+	// recent events don't vanish from calendar apps right away.
+	calendarFeedDays = 30
+)
+
+type CalendarHandler struct {
+	calendarService *services.CalendarService
+}
+
+func NewCalendarHandler() *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: services.NewCalendarService(),
+	}
+}
+
+// ShowCalendar renders the upcoming releases of the user's watchlist as
+// month grids, with the URL of their private feed.
+func (h *CalendarHandler) ShowCalendar(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	today := services.Today()
+	entries, err := h.calendarService.GetUpcoming(userModel, today)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading your release calendar",
+		})
+		return
+	}
+
+	data := gin.H{
+		"title":   "Calendar",
+		"user":    userModel,
+		"entries": entries,
+		"months":  services.CalendarMonths(entries, today, calendarMonths),
+		// Weeks start on Monday
+		"weekdays": []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
+	}
+	// Without a token the page still renders, without the feed URL
+	if token, err := h.calendarService.Token(userModel); err == nil {
+		data["feedURL"] = calendarFeedURL(c, token)
+		// webcal:// makes browsers hand the feed to the calendar app
+		data["subscribeURL"] = "webcal://" + c.Request.Host + "/calendar/" + token + ".ics"
+	}
+
+	render(c, http.StatusOK, "calendar.html", data)
+}
+
+// GetUpcoming returns the upcoming releases of the user's watchlist.
+func (h *CalendarHandler) GetUpcoming(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	entries, err := h.calendarService.GetUpcoming(userModel, services.Today())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading upcoming releases"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// ResetToken replaces the user's feed token, so the old feed URL stops
+// working.
+func (h *CalendarHandler) ResetToken(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	token, err := h.calendarService.ResetToken(userModel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error resetting the calendar link"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"feed_url": calendarFeedURL(c, token)})
+}
+
+// Feed serves the user's calendar as iCalendar to calendar apps, which
+// can't log in: the token in the URL authenticates the request.
+func (h *CalendarHandler) Feed(c *gin.Context) {
+	token, ok := strings.CutSuffix(c.Param("token"), ".ics")
+	if !ok || token == "" {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	user, err := h.calendarService.GetUserByToken(token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Status(http.StatusNotFound)
+		} else {
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	entries, err := h.calendarService.GetUpcoming(user, services.Today().AddDate(0, 0, -calendarFeedDays))
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	lang := i18n.Resolve(user.Language, c.GetHeader("Accept-Language"))
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Content-Disposition", `inline; filename="movie-releases.ics"`)
+	c.Status(http.StatusOK)
+	name := i18n.T(lang, "Movie Tracker releases")
+	if err := services.WriteICS(c.Writer, name, lang, baseURL(c), entries); err != nil {
+		c.Error(err)
+	}
+}
+
+// baseURL returns the scheme and host the request was made to, honouring a
+// reverse proxy's X-Forwarded-Proto.
+func baseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+func calendarFeedURL(c *gin.Context, token string) string {
+	return baseURL(c) + "/calendar/" + token + ".ics"
+}
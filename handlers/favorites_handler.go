@@ -1,22 +1,29 @@
 package handlers
 
 import (
+	"errors"
+	"movie-tracker/i18n"
 	"movie-tracker/models"
 	"movie-tracker/services"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type FavoritesHandler struct {
 	favoritesService *services.FavoritesService
+	providerService  *services.ProviderService
+	genreService     *services.GenreService
 	tmdbService      *services.TMDBService
 }
 
 func NewFavoritesHandler() *FavoritesHandler {
 	return &FavoritesHandler{
 		favoritesService: services.NewFavoritesService(),
+		providerService:  services.NewProviderService(),
+		genreService:     services.NewGenreService(),
 		tmdbService:      services.NewTMDBService(),
 	}
 }
@@ -26,41 +33,132 @@ func (h *FavoritesHandler) ShowFavorites(c *gin.Context) {
 	userModel := user.(*models.User)
 
 	statusParam := c.Query("status")
-	var status *models.Status
-	if statusParam != "" {
-		s := models.Status(statusParam)
-		status = &s
+	filter, err := favoritesFilterFromQuery(c, userModel)
+	if err != nil {
+		render(c, http.StatusBadRequest, "error.html", gin.H{
+			"title": "Error",
+			"error": err.Error(),
+		})
+		return
 	}
 
-	favorites, err := h.favoritesService.GetUserFavorites(userModel.ID, status, 0, 0)
+	favorites, err := h.favoritesService.GetUserFavorites(userModel.ID, filter, 0, 0)
 	if err != nil {
-		c.HTML(http.StatusInternalServerError, "favorites.html", gin.H{
+		render(c, http.StatusInternalServerError, "favorites.html", gin.H{
 			"title": "Favorites",
 			"error": "Error loading favorites",
 			"user":  userModel,
 		})
 		return
 	}
+	// Streaming badges are a nice-to-have: the list still renders without them
+	_ = h.providerService.FillStreamingOn(services.UserStreamingServices(userModel), favorites)
 
 	stats, _ := h.favoritesService.GetUserStats(userModel.ID)
+	genres, _ := h.favoritesService.GetGenreStats(userModel.ID)
 
-	c.HTML(http.StatusOK, "favorites.html", gin.H{
+	render(c, http.StatusOK, "favorites.html", gin.H{
 		"title":     "Favorites",
 		"user":      userModel,
 		"favorites": favorites,
 		"stats":     stats,
 		"status":    statusParam,
+		"genres":    genres,
+		"genre":     c.Query("genre"),
+		"query":     filter.Query,
+		"sortable":  filter.Sortable(),
+		"streamNow": filter.Streaming != nil,
 	})
 }
 
+// ListFavorites returns the user's favorites, optionally filtered by status,
+// genre, a full-text query (?q=) and what the user can stream now
+// (?stream=1). HTMX requests get the rendered list.
+func (h *FavoritesHandler) ListFavorites(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	filter, err := favoritesFilterFromQuery(c, userModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	favorites, err := h.favoritesService.GetUserFavorites(userModel.ID, filter, 0, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading favorites"})
+		return
+	}
+	_ = h.providerService.FillStreamingOn(services.UserStreamingServices(userModel), favorites)
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "favorite_list.html", gin.H{
+			"favorites": favorites,
+			"status":    c.Query("status"),
+			"query":     filter.Query,
+			"sortable":  filter.Sortable(),
+			"streamNow": filter.Streaming != nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, favorites)
+}
+
+func favoritesFilterFromQuery(c *gin.Context, user *models.User) (services.FavoritesFilter, error) {
+	var query formParser
+	var filter services.FavoritesFilter
+	if statusParam := c.Query("status"); statusParam != "" {
+		status := models.Status(statusParam)
+		filter.Status = &status
+	}
+	filter.GenreID = query.optionalInt("genre", c.Query("genre"))
+	filter.Tag = c.Query("tag")
+	filter.List = c.Query("list")
+	filter.Query = strings.TrimSpace(c.Query("q"))
+	if c.Query("stream") == "1" {
+		streaming := services.UserStreamingServices(user)
+		filter.Streaming = &streaming
+	}
+	return filter, query.err
+}
+
+// ShowSearch renders the search page with its discover sidebar. A URL with
+// discover filters (as pushed by DiscoverMovies) shows their results right
+// away.
 func (h *FavoritesHandler) ShowSearch(c *gin.Context) {
 	user, _ := c.Get("user")
 	userModel := user.(*models.User)
 
-	c.HTML(http.StatusOK, "search.html", gin.H{
-		"title": "Search Movies",
-		"user":  userModel,
-	})
+	filter, err := discoverFilterFromQuery(c)
+	if err != nil {
+		render(c, http.StatusBadRequest, "error.html", gin.H{
+			"title": "Error",
+			"error": err.Error(),
+		})
+		return
+	}
+	data := gin.H{
+		"title":     "Search Movies",
+		"user":      userModel,
+		"filter":    filter,
+		"genres":    h.genreService.WithLanguage(i18n.TMDBLocale(requestLanguage(c))).GetGenres(),
+		"languages": services.DiscoverLanguages(requestLanguage(c)),
+		"sorts":     services.DiscoverSortOptions,
+	}
+
+	if !filter.IsZero() || c.Query("page") != "" {
+		page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+		// On error the page still renders, and the sidebar can retry
+		if results, err := localTMDB(c, h.tmdbService).DiscoverMovies(filter, page); err == nil {
+			for key, value := range discoverResultsData(filter, results) {
+				data[key] = value
+			}
+			data["discover"] = true
+		}
+	}
+
+	render(c, http.StatusOK, "search.html", data)
 }
 
 func (h *FavoritesHandler) AddToFavorites(c *gin.Context) {
@@ -74,13 +172,17 @@ func (h *FavoritesHandler) AddToFavorites(c *gin.Context) {
 		return
 	}
 
-	tmdbMovie, err := h.tmdbService.GetMovieDetails(tmdbID)
+	tmdbMovie, err := localTMDB(c, h.tmdbService).GetMovieDetails(tmdbID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching movie details"})
 		return
 	}
 
 	status := models.Status(c.DefaultPostForm("status", string(models.StatusToBe)))
+	if !models.IsValidStatus(status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
 	notes := c.PostForm("notes")
 	recommendedBy := c.PostForm("recommended_by")
 
@@ -91,16 +193,16 @@ func (h *FavoritesHandler) AddToFavorites(c *gin.Context) {
 		}
 	}
 
-	favorite, err := h.favoritesService.AddToFavorites(userModel.ID, tmdbMovie, status, rating, notes, recommendedBy)
+	favorite, err := h.favoritesService.AddToFavorites(userModel.ID, tmdbMovie, status, rating, notes, recommendedBy, changeSource(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	if c.GetHeader("HX-Request") == "true" {
-		c.HTML(http.StatusOK, "alert.html", gin.H{
+		render(c, http.StatusOK, "alert.html", gin.H{
 			"type":    "success",
-			"message": "Movie added to favorites!",
+			"message": i18n.T(requestLanguage(c), "Movie added to favorites!"),
 		})
 		return
 	}
@@ -120,24 +222,37 @@ func (h *FavoritesHandler) UpdateStatus(c *gin.Context) {
 	}
 
 	status := models.Status(c.PostForm("status"))
-	if status != models.StatusToBe && status != models.StatusWatched && status != models.StatusRecommended {
+	if !models.IsValidStatus(status) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
 		return
 	}
 
-	_, err = h.favoritesService.UpdateStatus(uint(id), userModel.ID, status)
+	favorite, err := h.favoritesService.UpdateStatus(uint(id), userModel.ID, status, c.PostForm("reason"), changeSource(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	promptRating := services.NeedsRatingPrompt(favorite)
+
 	if c.GetHeader("HX-Request") == "true" {
+		if promptRating {
+			c.Header("HX-Retarget", "#alerts")
+			c.Header("HX-Reswap", "afterbegin")
+			render(c, http.StatusOK, "rating_prompt.html", gin.H{
+				"favorite": favorite,
+			})
+			return
+		}
 		c.Header("HX-Refresh", "true")
 		c.Status(http.StatusOK)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Status updated successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Status updated successfully",
+		"prompt_rating": promptRating,
+	})
 }
 
 func (h *FavoritesHandler) UpdateRating(c *gin.Context) {
@@ -158,7 +273,7 @@ func (h *FavoritesHandler) UpdateRating(c *gin.Context) {
 		return
 	}
 
-	_, err = h.favoritesService.UpdateRating(uint(id), userModel.ID, rating)
+	_, err = h.favoritesService.UpdateRating(uint(id), userModel.ID, rating, changeSource(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -173,7 +288,9 @@ func (h *FavoritesHandler) UpdateRating(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Rating updated successfully"})
 }
 
-func (h *FavoritesHandler) DeleteFavorite(c *gin.Context) {
+// UpdateProgress records how far into a movie the user is, either as
+// "minutes" watched or as a "percent" of the runtime.
+func (h *FavoritesHandler) UpdateProgress(c *gin.Context) {
 	user, _ := c.Get("user")
 	userModel := user.(*models.User)
 
@@ -184,7 +301,22 @@ func (h *FavoritesHandler) DeleteFavorite(c *gin.Context) {
 		return
 	}
 
-	err = h.favoritesService.DeleteFavorite(uint(id), userModel.ID)
+	var favorite *models.FavoriteMovie
+	if percentStr := c.PostForm("percent"); percentStr != "" {
+		percent, convErr := strconv.Atoi(percentStr)
+		if convErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid percent"})
+			return
+		}
+		favorite, err = h.favoritesService.UpdateProgressPercent(uint(id), userModel.ID, percent, changeSource(c))
+	} else {
+		minutes, convErr := strconv.Atoi(c.PostForm("minutes"))
+		if convErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid minutes"})
+			return
+		}
+		favorite, err = h.favoritesService.UpdateProgress(uint(id), userModel.ID, minutes, changeSource(c))
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -196,5 +328,358 @@ func (h *FavoritesHandler) DeleteFavorite(c *gin.Context) {
 		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Progress updated successfully",
+		"progress_minutes": favorite.ProgressMinutes,
+		"progress_percent": favorite.ProgressPercent(),
+	})
+}
+
+func (h *FavoritesHandler) DeleteFavorite(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	err = h.favoritesService.DeleteFavorite(uint(id), userModel.ID, changeSource(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The card swaps itself out; the alert is placed out-of-band with an undo button
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "alert.html", gin.H{
+			"type":    "info",
+			"message": i18n.T(requestLanguage(c), "Movie moved to trash"),
+			"undo":    "/api/favorites/" + idStr + "/restore",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Favorite deleted successfully"})
-}
\ No newline at end of file
+}
+
+// BulkUpdate applies a status, rating, tag, list or delete action to many
+// favorites at once and reports the result for each of them.
+func (h *FavoritesHandler) BulkUpdate(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	var op services.BulkOperation
+	if err := c.ShouldBind(&op); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bulk request"})
+		return
+	}
+
+	results, err := h.favoritesService.BulkUpdate(userModel.ID, op, changeSource(c))
+	if err != nil {
+		if c.GetHeader("HX-Request") == "true" {
+			render(c, http.StatusOK, "alert.html", gin.H{
+				"type":    "error",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":   results,
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+	})
+}
+
+func (h *FavoritesHandler) ShowTrash(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	favorites, err := h.favoritesService.GetTrash(userModel.ID)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "trash.html", gin.H{
+			"title": "Trash",
+			"error": "Error loading trash",
+			"user":  userModel,
+		})
+		return
+	}
+
+	render(c, http.StatusOK, "trash.html", gin.H{
+		"title":         "Trash",
+		"user":          userModel,
+		"favorites":     favorites,
+		"retentionDays": int(services.TrashRetention().Hours() / 24),
+	})
+}
+
+func (h *FavoritesHandler) RestoreFavorite(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	favorite, err := h.favoritesService.RestoreFavorite(uint(id), userModel.ID, changeSource(c))
+	if err != nil {
+		if c.GetHeader("HX-Request") == "true" {
+			render(c, http.StatusOK, "alert.html", gin.H{
+				"type":    "error",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, favorite)
+}
+
+func (h *FavoritesHandler) PurgeFavorite(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := h.favoritesService.PurgeFavorite(uint(id), userModel.ID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Favorite permanently deleted"})
+}
+
+func (h *FavoritesHandler) EmptyTrash(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := h.favoritesService.EmptyTrash(userModel.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to empty trash"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Trash emptied"})
+}
+
+func (h *FavoritesHandler) ListTrash(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	favorites, err := h.favoritesService.GetTrash(userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading trash"})
+		return
+	}
+
+	c.JSON(http.StatusOK, favorites)
+}
+
+func (h *FavoritesHandler) GetHistory(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	history, err := h.favoritesService.GetFavoriteHistory(uint(id), userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "favorite_history.html", gin.H{
+			"history": history,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// MoveFavorite reorders the watchlist after a drag and drop. The dropped
+// movie goes between after_id (the movie now above it) and before_id (the
+// movie now below it).
+func (h *FavoritesHandler) MoveFavorite(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	favorite, err := h.favoritesService.MoveFavorite(uint(id), userModel.ID, optionalID(c.PostForm("after_id")), optionalID(c.PostForm("before_id")))
+	if err != nil {
+		if c.GetHeader("HX-Request") == "true" {
+			c.Header("HX-Reswap", "none")
+			render(c, http.StatusOK, "alert.html", gin.H{
+				"type":    "error",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Watchlist reordered",
+		"position": favorite.Position,
+	})
+}
+
+// MoveToTop puts a movie first on the watchlist. From search results the
+// movie is identified by tmdb_id and is added to the collection if needed.
+func (h *FavoritesHandler) MoveToTop(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	var favorite *models.FavoriteMovie
+	var err error
+	if idStr := c.Param("id"); idStr != "" {
+		id, convErr := strconv.ParseUint(idStr, 10, 32)
+		if convErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+			return
+		}
+		favorite, err = h.favoritesService.MoveToTop(uint(id), userModel.ID, changeSource(c))
+	} else {
+		tmdbID, convErr := strconv.Atoi(c.PostForm("tmdb_id"))
+		if convErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid TMDB ID"})
+			return
+		}
+		favorite, err = h.moveTMDBMovieToTop(c, userModel.ID, tmdbID)
+	}
+	if err != nil {
+		if c.GetHeader("HX-Request") == "true" {
+			render(c, http.StatusOK, "alert.html", gin.H{
+				"type":    "error",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		if c.Param("id") != "" {
+			c.Header("HX-Refresh", "true")
+			c.Status(http.StatusOK)
+			return
+		}
+		render(c, http.StatusOK, "alert.html", gin.H{
+			"type":    "success",
+			"message": i18n.T(requestLanguage(c), "%s is now at the top of your watchlist", favorite.Title),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, favorite)
+}
+
+func (h *FavoritesHandler) moveTMDBMovieToTop(c *gin.Context, userID uint, tmdbID int) (*models.FavoriteMovie, error) {
+	if existing, err := h.favoritesService.GetFavoriteByTMDBID(userID, tmdbID); err == nil {
+		return h.favoritesService.MoveToTop(existing.ID, userID, changeSource(c))
+	}
+
+	tmdbMovie, err := localTMDB(c, h.tmdbService).GetMovieDetails(tmdbID)
+	if err != nil {
+		return nil, errors.New("error fetching movie details")
+	}
+
+	// New movies are added at the top of the watchlist
+	return h.favoritesService.AddToFavorites(userID, tmdbMovie, models.StatusToBe, nil, "", "", changeSource(c))
+}
+
+func (h *FavoritesHandler) UpdatePriority(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	priority, err := strconv.Atoi(c.PostForm("priority"))
+	if err != nil || !models.IsValidPriority(models.Priority(priority)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid priority"})
+		return
+	}
+
+	_, err = h.favoritesService.UpdatePriority(uint(id), userModel.ID, models.Priority(priority), changeSource(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Priority updated successfully"})
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"movie-tracker/models"
+	"movie-tracker/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// userSearchSize is how many users a people search returns.
+	userSearchSize = 20
+	// feedSize is how many activities the dashboard feed shows.
+	feedSize = 30
+)
+
+type FollowHandler struct {
+	followService *services.FollowService
+}
+
+func NewFollowHandler() *FollowHandler {
+	return &FollowHandler{
+		followService: services.NewFollowService(),
+	}
+}
+
+// ShowPeople renders who the user follows, their followers and their
+// pending follow requests, with a search to find more people.
+func (h *FollowHandler) ShowPeople(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	following, err := h.followService.GetFollowing(userModel.ID)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading the people you follow",
+		})
+		return
+	}
+	followers, err := h.followService.GetFollowers(userModel.ID, models.FollowAccepted)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading your followers",
+		})
+		return
+	}
+	requests, err := h.followService.GetFollowers(userModel.ID, models.FollowPending)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading your follow requests",
+		})
+		return
+	}
+
+	render(c, http.StatusOK, "people.html", gin.H{
+		"title":     "People",
+		"user":      userModel,
+		"following": following,
+		"followers": followers,
+		"requests":  requests,
+	})
+}
+
+// SearchUsers finds other users by username (?q=). HTMX requests get the
+// results with a follow button each.
+func (h *FollowHandler) SearchUsers(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	query := c.Query("q")
+	users, err := h.followService.SearchUsers(query, userModel.ID, userSearchSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error searching users"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		statuses, err := h.followService.FollowStatuses(userModel.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error searching users"})
+			return
+		}
+		render(c, http.StatusOK, "user_results.html", gin.H{
+			"users":    users,
+			"statuses": statuses,
+			"query":    strings.TrimSpace(query),
+		})
+		return
+	}
+
+	profiles := make([]models.UserProfile, len(users))
+	for i := range users {
+		profiles[i] = users[i].Profile()
+	}
+	c.JSON(http.StatusOK, profiles)
+}
+
+// ListFollows returns who the user follows, their followers and their
+// pending follow requests.
+func (h *FollowHandler) ListFollows(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	following, err := h.followService.GetFollowing(userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading follows"})
+		return
+	}
+	followers, err := h.followService.GetFollowers(userModel.ID, models.FollowAccepted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading follows"})
+		return
+	}
+	requests, err := h.followService.GetFollowers(userModel.ID, models.FollowPending)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading follows"})
+		return
+	}
+
+	followingJSON := make([]gin.H, len(following))
+	for i, follow := range following {
+		followingJSON[i] = gin.H{"user": follow.Followee.Profile(), "status": follow.Status, "created_at": follow.CreatedAt}
+	}
+	followersJSON := make([]gin.H, len(followers))
+	for i, follow := range followers {
+		followersJSON[i] = gin.H{"user": follow.Follower.Profile(), "accepted_at": follow.AcceptedAt}
+	}
+	requestsJSON := make([]gin.H, len(requests))
+	for i, follow := range requests {
+		requestsJSON[i] = gin.H{"user": follow.Follower.Profile(), "created_at": follow.CreatedAt}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"following": followingJSON,
+		"followers": followersJSON,
+		"requests":  requestsJSON,
+	})
+}
+
+// Follow follows another user by username, or asks to if their profile is
+// private.
+func (h *FollowHandler) Follow(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	var req struct {
+		Username string `json:"username" form:"username" binding:"required"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	follow, err := h.followService.Follow(userModel, strings.TrimSpace(req.Username))
+	if err != nil {
+		if c.GetHeader("HX-Request") == "true" {
+			render(c, http.StatusOK, "alert.html", gin.H{
+				"type":    "error",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, follow)
+}
+
+// Unfollow stops following a user, or cancels a follow request.
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.followService.Unfollow(userModel.ID, uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "You don't follow this user"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unfollowed"})
+}
+
+// AcceptFollower accepts a pending follow request.
+func (h *FollowHandler) AcceptFollower(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.followService.Accept(userModel, uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Follow request not found"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Follow request accepted"})
+}
+
+// RemoveFollower declines a pending follow request, or removes a follower.
+func (h *FollowHandler) RemoveFollower(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.followService.RemoveFollower(userModel.ID, uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Follower not found"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Follower removed"})
+}
+
+// GetFeed returns the latest activity of the users the user follows. HTMX
+// requests get the dashboard feed.
+func (h *FollowHandler) GetFeed(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	items, err := h.followService.GetFeed(userModel.ID, feedSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading feed"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "feed.html", gin.H{
+			"items": items,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"movie-tracker/models"
+	"movie-tracker/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// firstFilmDecade is the earliest decade a goal can target: the one of the
+// first motion pictures.
+const firstFilmDecade = 1870
+
+type GoalsHandler struct {
+	goalsService *services.GoalsService
+}
+
+func NewGoalsHandler() *GoalsHandler {
+	return &GoalsHandler{
+		goalsService: services.NewGoalsService(),
+	}
+}
+
+func (h *GoalsHandler) GetGoals(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	h.respondWithGoals(c, userModel.ID, http.StatusOK)
+}
+
+func (h *GoalsHandler) CreateGoal(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	target, err := strconv.Atoi(c.PostForm("target"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid target"})
+		return
+	}
+
+	var form formParser
+	goal := &models.Goal{
+		UserID:           userModel.ID,
+		Title:            c.PostForm("title"),
+		Target:           target,
+		StartsAt:         form.optionalDate("starts_at", c.PostForm("starts_at")),
+		EndsAt:           form.optionalDate("ends_at", c.PostForm("ends_at")),
+		GenreID:          form.optionalInt("genre_id", c.PostForm("genre_id")),
+		ReleaseYearFrom:  form.optionalInt("release_year_from", c.PostForm("release_year_from")),
+		ReleaseYearTo:    form.optionalInt("release_year_to", c.PostForm("release_year_to")),
+		OriginalLanguage: c.PostForm("original_language"),
+		MinRuntime:       form.optionalInt("min_runtime", c.PostForm("min_runtime")),
+		MaxRuntime:       form.optionalInt("max_runtime", c.PostForm("max_runtime")),
+	}
+
+	// A decade shortcut ("1960") fills in both ends of the release year range
+	if decade := form.optionalInt("decade", c.PostForm("decade")); decade != nil {
+		if *decade%10 != 0 || *decade < firstFilmDecade || *decade > time.Now().Year() {
+			form.fail("decade")
+		} else {
+			from, to := *decade, *decade+9
+			goal.ReleaseYearFrom = &from
+			goal.ReleaseYearTo = &to
+		}
+	}
+
+	err = form.err
+	if err == nil {
+		err = h.goalsService.CreateGoal(goal)
+	}
+	if err != nil {
+		if c.GetHeader("HX-Request") == "true" {
+			c.Header("HX-Reswap", "none")
+			render(c, http.StatusOK, "alert.html", gin.H{
+				"type":    "error",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		h.respondWithGoals(c, userModel.ID, http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusCreated, goal)
+}
+
+func (h *GoalsHandler) DeleteGoal(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := h.goalsService.DeleteGoal(uint(id), userModel.ID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		h.respondWithGoals(c, userModel.ID, http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Goal deleted successfully"})
+}
+
+func (h *GoalsHandler) respondWithGoals(c *gin.Context, userID uint, code int) {
+	progress, err := h.goalsService.GetGoalProgress(userID, requestLanguage(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get goals"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, code, "goals.html", gin.H{
+			"goals": progress,
+		})
+		return
+	}
+
+	c.JSON(code, progress)
+}
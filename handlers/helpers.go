@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"fmt"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"movie-tracker/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// changeSource tells HTMX requests from the web UI apart from direct API calls.
+func changeSource(c *gin.Context) models.ChangeSource {
+	if c.GetHeader("HX-Request") == "true" {
+		return models.SourceWeb
+	}
+	return models.SourceAPI
+}
+
+// requestLanguage is the UI language of the request: the user's choice, or
+// the best match for the browser's Accept-Language header.
+func requestLanguage(c *gin.Context) string {
+	var userLanguage string
+	if user, ok := c.Get("user"); ok {
+		userLanguage = user.(*models.User).Language
+	}
+	return i18n.Resolve(userLanguage, c.GetHeader("Accept-Language"))
+}
+
+// render is c.HTML for every template. gin.H data also gets the request's
+// language as "lang", for the t template function, and its path as "path",
+// for the navigation bar.
+func render(c *gin.Context, code int, name string, data interface{}) {
+	if h, ok := data.(gin.H); ok {
+		h["lang"] = requestLanguage(c)
+		h["path"] = c.Request.URL.Path
+	}
+	c.HTML(code, name, data)
+}
+
+// tmdbLocale returns the TMDB language and region for the request: its UI
+// language and the user's region.
+func tmdbLocale(c *gin.Context) (language, region string) {
+	if user, ok := c.Get("user"); ok {
+		region = user.(*models.User).Region
+	}
+	return i18n.TMDBLocale(requestLanguage(c)), region
+}
+
+// localTMDB returns tmdb localized for the request.
+func localTMDB(c *gin.Context, tmdb *services.TMDBService) *services.TMDBService {
+	return tmdb.WithLocale(tmdbLocale(c))
+}
+
+// formParser parses optional form and query values. Empty values are nil;
+// the first invalid one is kept in err so the handler can answer 400 rather
+// than silently ignore it.
+type formParser struct {
+	err error
+}
+
+// optionalInt parses the value of field name.
+func (p *formParser) optionalInt(name, value string) *int {
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		p.fail(name)
+		return nil
+	}
+	return &n
+}
+
+// optionalFloat parses the value of field name.
+func (p *formParser) optionalFloat(name, value string) *float64 {
+	if value == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		p.fail(name)
+		return nil
+	}
+	return &f
+}
+
+// optionalDate parses the YYYY-MM-DD value of field name.
+func (p *formParser) optionalDate(name, value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		p.fail(name)
+		return nil
+	}
+	return &t
+}
+
+func (p *formParser) fail(name string) {
+	if p.err == nil {
+		p.err = fmt.Errorf("invalid %s", name)
+	}
+}
+
+// optionalID parses an ID form value, returning nil when it is empty or invalid.
+func optionalID(value string) *uint {
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil
+	}
+	id := uint(n)
+	return &id
+}
+
+// intValues parses repeated form values, skipping empty and invalid ones.
+func intValues(values []string) []int {
+	var result []int
+	for _, value := range values {
+		if n, err := strconv.Atoi(value); err == nil {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// formCheckbox reads a checkbox sent after a hidden "off" input of the same
+// name, so unchecked boxes are posted too. It returns nil when the field
+// isn't in the form at all, leaving the setting unchanged.
+func formCheckbox(c *gin.Context, name string) *bool {
+	values, ok := c.GetPostFormArray(name)
+	if !ok {
+		return nil
+	}
+	checked := false
+	for _, value := range values {
+		if value == "on" {
+			checked = true
+		}
+	}
+	return &checked
+}
+
+// formString returns a form value, or nil when the field isn't in the form.
+func formString(c *gin.Context, name string) *string {
+	value, ok := c.GetPostForm(name)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+// formInts parses repeated form values as intValues does, or returns nil
+// when the field isn't in the form. A hidden empty input of the same name
+// makes an empty selection tell apart from a missing field.
+func formInts(c *gin.Context, name string) *[]int {
+	values, ok := c.GetPostFormArray(name)
+	if !ok {
+		return nil
+	}
+	ints := intValues(values)
+	return &ints
+}
+
+// negated returns !*b, or nil if b is nil.
+func negated(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	not := !*b
+	return &not
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"movie-tracker/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MovieRecommendationHandler struct {
+	movieRecommendationService *services.MovieRecommendationService
+}
+
+func NewMovieRecommendationHandler() *MovieRecommendationHandler {
+	return &MovieRecommendationHandler{
+		movieRecommendationService: services.NewMovieRecommendationService(),
+	}
+}
+
+// ShowRecommended renders the movies other users recommended to the user,
+// and the ones the user recommended with what became of them.
+func (h *MovieRecommendationHandler) ShowRecommended(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	received, err := h.movieRecommendationService.GetReceived(userModel.ID)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading your recommendations",
+		})
+		return
+	}
+	sent, err := h.movieRecommendationService.GetSent(userModel.ID)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading your recommendations",
+		})
+		return
+	}
+
+	render(c, http.StatusOK, "recommended.html", gin.H{
+		"title":    "Recommended to you",
+		"user":     userModel,
+		"received": received,
+		"sent":     sent,
+	})
+}
+
+// ListRecommended returns the recommendations the user received and sent.
+func (h *MovieRecommendationHandler) ListRecommended(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	received, err := h.movieRecommendationService.GetReceived(userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading recommendations"})
+		return
+	}
+	sent, err := h.movieRecommendationService.GetSent(userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading recommendations"})
+		return
+	}
+
+	receivedJSON := make([]gin.H, len(received))
+	for i, recommendation := range received {
+		receivedJSON[i] = gin.H{"recommendation": recommendation, "sender": recommendation.Sender.Profile()}
+	}
+	sentJSON := make([]gin.H, len(sent))
+	for i, recommendation := range sent {
+		entry := gin.H{"recommendation": recommendation, "recipient": recommendation.Recipient.Profile()}
+		if recommendation.FavoriteMovie != nil {
+			entry["favorite_status"] = recommendation.FavoriteMovie.Status
+			entry["favorite_rating"] = recommendation.FavoriteMovie.Rating
+		}
+		sentJSON[i] = entry
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"received": receivedJSON,
+		"sent":     sentJSON,
+	})
+}
+
+// Recommend recommends a TMDB movie to another user, with an optional
+// message.
+func (h *MovieRecommendationHandler) Recommend(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	tmdbID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
+		return
+	}
+
+	var req struct {
+		RecipientID uint   `json:"recipient_id" form:"recipient_id" binding:"required"`
+		Message     string `json:"message" form:"message"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	recommendation, err := h.movieRecommendationService.WithLocale(tmdbLocale(c)).Recommend(userModel, req.RecipientID, tmdbID, req.Message)
+	if err != nil {
+		if c.GetHeader("HX-Request") == "true" {
+			render(c, http.StatusOK, "alert.html", gin.H{
+				"type":    "error",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Trigger", "movieRecommended")
+		render(c, http.StatusOK, "alert.html", gin.H{
+			"type":    "success",
+			"message": i18n.T(requestLanguage(c), "Recommendation sent!"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, recommendation)
+}
+
+// Accept adds a recommended movie to the user's favorites.
+func (h *MovieRecommendationHandler) Accept(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recommendation ID"})
+		return
+	}
+
+	recommendation, err := h.movieRecommendationService.WithLocale(tmdbLocale(c)).Accept(uint(id), userModel.ID, changeSource(c))
+	if err != nil {
+		if c.GetHeader("HX-Request") == "true" {
+			render(c, http.StatusOK, "alert.html", gin.H{
+				"type":    "error",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendation)
+}
+
+// Decline turns down a recommendation.
+func (h *MovieRecommendationHandler) Decline(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recommendation ID"})
+		return
+	}
+
+	recommendation, err := h.movieRecommendationService.Decline(uint(id), userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendation)
+}
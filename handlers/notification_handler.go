@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"movie-tracker/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inboxSize is how many notifications the inbox shows.
+const inboxSize = 100
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler() *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: services.NewNotificationService(),
+	}
+}
+
+// ShowNotifications renders the user's in-app inbox.
+func (h *NotificationHandler) ShowNotifications(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	notifications, err := h.notificationService.GetInbox(userModel.ID, inboxSize)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading your notifications",
+		})
+		return
+	}
+
+	render(c, http.StatusOK, "notifications.html", gin.H{
+		"title":         "Notifications",
+		"user":          userModel,
+		"notifications": notifications,
+	})
+}
+
+// ListNotifications returns the user's in-app notifications, newest first.
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	notifications, err := h.notificationService.GetInbox(userModel.ID, inboxSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// GetUnreadCount returns how many notifications are unread. HTMX requests
+// get the badge shown next to the bell in the navigation bar.
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	count, err := h.notificationService.UnreadCount(userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error counting notifications"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "notification_badge.html", gin.H{
+			"unread": count,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread": count})
+}
+
+// OpenNotification marks a notification read and redirects to what it is
+// about.
+func (h *NotificationHandler) OpenNotification(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/notifications")
+		return
+	}
+
+	notification, err := h.notificationService.MarkRead(uint(id), userModel.ID)
+	if err != nil || notification.URL == "" {
+		c.Redirect(http.StatusFound, "/notifications")
+		return
+	}
+
+	c.Redirect(http.StatusFound, notification.URL)
+}
+
+// MarkRead marks one notification read.
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	notification, err := h.notificationService.MarkRead(uint(id), userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
+
+// MarkAllRead marks every notification of the user read.
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	if err := h.notificationService.MarkAllRead(userModel.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error marking notifications read"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked read"})
+}
+
+// UpdatePreferences saves the user's notification preferences. Each enabled
+// channel of a type is a checkbox named "<type>.<channel>".
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	settings := services.NotificationSettings{
+		Channels:     make(map[models.NotificationType]map[models.NotificationChannel]bool),
+		Webhook:      strings.TrimSpace(c.PostForm("notification_webhook")),
+		WeeklyDigest: c.PostForm("weekly_digest") == "on",
+	}
+	for _, def := range models.NotificationTypes {
+		settings.Channels[def.Type] = make(map[models.NotificationChannel]bool)
+		for _, channel := range models.NotificationChannels {
+			settings.Channels[def.Type][channel.Channel] = c.PostForm(string(def.Type)+"."+string(channel.Channel)) == "on"
+		}
+	}
+
+	if err := h.notificationService.UpdatePreferences(userModel, settings); err != nil {
+		if c.GetHeader("HX-Request") == "true" {
+			render(c, http.StatusOK, "alert.html", gin.H{
+				"type":    "error",
+				"message": i18n.T(requestLanguage(c), "Failed to save notification preferences"),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "alert.html", gin.H{
+			"type":    "success",
+			"message": i18n.T(requestLanguage(c), "Notification preferences saved"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"movie-tracker/models"
+	"movie-tracker/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PickerHandler struct {
+	pickerService *services.PickerService
+}
+
+func NewPickerHandler() *PickerHandler {
+	return &PickerHandler{
+		pickerService: services.NewPickerService(),
+	}
+}
+
+// Pick chooses a movie from the watchlist for tonight. Constraints come from
+// the query string: max_runtime, genre and exclude_genre (repeatable), mood
+// (repeatable tag name), decade and recommended_by.
+func (h *PickerHandler) Pick(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	constraints, err := pickerConstraintsFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.pickerService.Pick(userModel.ID, constraints, requestLanguage(c))
+	if err != nil && !errors.Is(err, services.ErrNothingToPick) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error picking a movie"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		data := gin.H{"result": result}
+		if err != nil {
+			data["error"] = err.Error()
+		}
+		render(c, http.StatusOK, "picker_result.html", data)
+		return
+	}
+
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Snooze hides a movie from the picker until tomorrow. HTMX requests trigger
+// a new pick with the same constraints.
+func (h *PickerHandler) Snooze(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	favorite, err := h.pickerService.Snooze(uint(id), userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Trigger", "pick-again")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Movie snoozed",
+		"snoozed_until": favorite.SnoozedUntil,
+	})
+}
+
+func pickerConstraintsFromQuery(c *gin.Context) (services.PickerConstraints, error) {
+	var query formParser
+	constraints := services.PickerConstraints{
+		MaxRuntime:    query.optionalInt("max_runtime", c.Query("max_runtime")),
+		IncludeGenres: intValues(c.QueryArray("genre")),
+		ExcludeGenres: intValues(c.QueryArray("exclude_genre")),
+		Decade:        query.optionalInt("decade", c.Query("decade")),
+		RecommendedBy: c.Query("recommended_by"),
+	}
+	for _, mood := range c.QueryArray("mood") {
+		if mood != "" {
+			constraints.Moods = append(constraints.Moods, mood)
+		}
+	}
+	return constraints, query.err
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"movie-tracker/services"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// publicRatingsSize is how many ratings a public page shows.
+const publicRatingsSize = 60
+
+// robotsTxt lets crawlers into public pages only. Each page still says
+// whether it may be indexed, following its owner's choice.
+const robotsTxt = `User-agent: *
+Allow: /u/
+Disallow: /
+`
+
+type PublicPageHandler struct {
+	publicPageService *services.PublicPageService
+}
+
+func NewPublicPageHandler() *PublicPageHandler {
+	return &PublicPageHandler{
+		publicPageService: services.NewPublicPageService(),
+	}
+}
+
+// ShowProfile renders a user's public page, without login: their public
+// lists and, if they chose to share them, their ratings.
+func (h *PublicPageHandler) ShowProfile(c *gin.Context) {
+	owner, err := h.publicPageService.GetUser(c.Param("username"))
+	if err != nil {
+		h.renderError(c, err)
+		return
+	}
+
+	lists, err := h.publicPageService.GetPublicLists(owner.ID)
+	if err != nil {
+		h.renderError(c, err)
+		return
+	}
+	var ratings []models.FavoriteMovie
+	if owner.PublicRatings {
+		ratings, err = h.publicPageService.GetRatings(owner.ID, publicRatingsSize)
+		if err != nil {
+			h.renderError(c, err)
+			return
+		}
+	}
+
+	robots := robotsContent(owner.AllowIndexing)
+	c.Header("X-Robots-Tag", robots)
+
+	lang := requestLanguage(c)
+	description := i18n.T(lang, "Movie lists shared by %s.", owner.Username)
+	if owner.PublicRatings {
+		description = i18n.T(lang, "Movies rated and lists shared by %s.", owner.Username)
+	}
+	data := gin.H{
+		"title":           owner.Username,
+		"owner":           owner,
+		"lists":           lists,
+		"ratings":         ratings,
+		"robots":          robots,
+		"pageURL":         baseURL(c) + "/u/" + url.PathEscape(owner.Username),
+		"pageTitle":       i18n.T(lang, "%s on Movie Tracker", owner.Username),
+		"pageDescription": description,
+	}
+	for _, favorite := range ratings {
+		if favorite.PosterPath != "" {
+			data["pageImage"] = favorite.PosterPath
+			break
+		}
+	}
+	render(c, http.StatusOK, "public_profile.html", data)
+}
+
+// ShowList renders a shared list, without login. Public lists are open to
+// anyone while their owner's public page is on; unlisted ones need the
+// share token (?token=) and are never indexed.
+func (h *PublicPageHandler) ShowList(c *gin.Context) {
+	page, err := h.publicPageService.GetList(c.Param("username"), c.Param("slug"), c.Query("token"))
+	if err != nil {
+		h.renderError(c, err)
+		return
+	}
+
+	robots := robotsContent(page.Owner.AllowIndexing && !page.Unlisted)
+	c.Header("X-Robots-Tag", robots)
+	pageURL := baseURL(c) + "/u/" + url.PathEscape(page.Owner.Username) + "/lists/" + url.PathEscape(page.List.Slug)
+	if page.Unlisted {
+		// Keep the token out of the Referer sent to TMDB and linked sites
+		c.Header("Referrer-Policy", "no-referrer")
+		pageURL += "?token=" + url.QueryEscape(c.Query("token"))
+	}
+
+	lang := requestLanguage(c)
+	description := page.List.Description
+	if description == "" {
+		description = i18n.T(lang, "A movie list by %s.", page.Owner.Username)
+	}
+	data := gin.H{
+		"title":           page.List.Name,
+		"owner":           page.Owner,
+		"list":            page.List,
+		"movies":          page.Movies,
+		"unlisted":        page.Unlisted,
+		"robots":          robots,
+		"pageURL":         pageURL,
+		"pageTitle":       i18n.T(lang, "%s · a list by %s", page.List.Name, page.Owner.Username),
+		"pageDescription": description,
+	}
+	for _, favorite := range page.Movies {
+		if favorite.PosterPath != "" {
+			data["pageImage"] = favorite.PosterPath
+			break
+		}
+	}
+	render(c, http.StatusOK, "public_list.html", data)
+}
+
+// RobotsTxt serves robots.txt.
+func (h *PublicPageHandler) RobotsTxt(c *gin.Context) {
+	c.String(http.StatusOK, robotsTxt)
+}
+
+// SetListVisibility changes who can see one of the user's lists (form
+// visibility: private, unlisted or public). HTMX requests get the list
+// sharing settings back.
+func (h *PublicPageHandler) SetListVisibility(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid list ID"})
+		return
+	}
+
+	list, err := h.publicPageService.SetListVisibility(uint(id), userModel.ID, models.ListVisibility(c.PostForm("visibility")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.respondList(c, userModel, list)
+}
+
+// ResetListToken gives one of the user's lists a new share link, so the old
+// one stops working.
+func (h *PublicPageHandler) ResetListToken(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid list ID"})
+		return
+	}
+
+	list, err := h.publicPageService.ResetListToken(uint(id), userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.respondList(c, userModel, list)
+}
+
+func (h *PublicPageHandler) respondList(c *gin.Context, user *models.User, list *models.MovieList) {
+	if c.GetHeader("HX-Request") == "true" {
+		lists, err := h.publicPageService.GetLists(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading your lists"})
+			return
+		}
+		render(c, http.StatusOK, "list_sharing.html", gin.H{
+			"user":      user,
+			"lists":     lists,
+			"shareURLs": listShareURLs(c, user, lists),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"list":      list,
+		"share_url": listShareURL(c, user, list),
+	})
+}
+
+func (h *PublicPageHandler) renderError(c *gin.Context, err error) {
+	c.Header("X-Robots-Tag", robotsContent(false))
+	if errors.Is(err, services.ErrPublicPageNotFound) {
+		render(c, http.StatusNotFound, "error.html", gin.H{
+			"title": "Not Found",
+			"error": "This page doesn't exist or isn't shared",
+		})
+		return
+	}
+	render(c, http.StatusInternalServerError, "error.html", gin.H{
+		"title": "Error",
+		"error": "Error loading this page",
+	})
+}
+
+// robotsContent is the robots meta tag and X-Robots-Tag value of a public
+// page.
+func robotsContent(index bool) string {
+	if index {
+		return "index, follow"
+	}
+	return "noindex, nofollow"
+}
+
+// listShareURL is the link to share list by: its plain URL when it shows on
+// the user's public page, the one with its token otherwise, or empty for
+// private lists.
+func listShareURL(c *gin.Context, user *models.User, list *models.MovieList) string {
+	if list.Visibility == models.ListPrivate || list.ShareToken == nil {
+		return ""
+	}
+	shareURL := baseURL(c) + "/u/" + url.PathEscape(user.Username) + "/lists/" + url.PathEscape(list.Slug)
+	if list.Visibility == models.ListPublic && user.PublicPage {
+		return shareURL
+	}
+	return shareURL + "?token=" + *list.ShareToken
+}
+
+func listShareURLs(c *gin.Context, user *models.User, lists []models.MovieList) map[uint]string {
+	shareURLs := make(map[uint]string, len(lists))
+	for i := range lists {
+		shareURLs[lists[i].ID] = listShareURL(c, user, &lists[i])
+	}
+	return shareURLs
+}
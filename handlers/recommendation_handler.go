@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"movie-tracker/models"
+	"movie-tracker/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecommendationHandler struct {
+	recommendationService *services.RecommendationService
+	similarityService     *services.SimilarityService
+}
+
+func NewRecommendationHandler() *RecommendationHandler {
+	return &RecommendationHandler{
+		recommendationService: services.NewRecommendationService(),
+		similarityService:     services.NewSimilarityService(),
+	}
+}
+
+// GetRecommendations suggests movies based on the user's ratings. HTMX
+// requests get the rendered "For you" section.
+func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "12"))
+	if limit <= 0 || limit > 40 {
+		limit = 12
+	}
+
+	recommendations, err := h.recommendationService.WithLocale(tmdbLocale(c)).GetRecommendations(userModel.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading recommendations"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "recommendations.html", gin.H{
+			"recommendations": recommendations,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendations)
+}
+
+// GetTasteSuggestions returns movies loved by users with similar taste.
+func (h *RecommendationHandler) GetTasteSuggestions(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "8"))
+	if limit <= 0 || limit > 40 {
+		limit = 8
+	}
+
+	var suggestions []services.TasteSuggestion
+	if !userModel.TasteMatchingOptOut {
+		var err error
+		suggestions, err = h.similarityService.GetSuggestions(userModel.ID, limit, requestLanguage(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading suggestions"})
+			return
+		}
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "taste_suggestions.html", gin.H{
+			"suggestions": suggestions,
+			"optedOut":    userModel.TasteMatchingOptOut,
+		})
+		return
+	}
+
+	if suggestions == nil {
+		suggestions = []services.TasteSuggestion{}
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"movie-tracker/services"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SettingsHandler struct {
+	settingsService     *services.SettingsService
+	providerService     *services.ProviderService
+	notificationService *services.NotificationService
+	publicPageService   *services.PublicPageService
+}
+
+func NewSettingsHandler() *SettingsHandler {
+	return &SettingsHandler{
+		settingsService:     services.NewSettingsService(),
+		providerService:     services.NewProviderService(),
+		notificationService: services.NewNotificationService(),
+		publicPageService:   services.NewPublicPageService(),
+	}
+}
+
+func (h *SettingsHandler) ShowSettings(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	// If TMDB is unreachable the page still renders, without the choices
+	regions, _ := h.providerService.GetRegions()
+	var providers []models.TMDBWatchProvider
+	if userModel.Region != "" {
+		providers, _ = h.providerService.GetProviders(userModel.Region)
+	}
+	// Without them the form shows every type's default channels
+	preferences, _ := h.notificationService.GetPreferences(userModel.ID)
+	// Without them the list sharing section is empty
+	lists, _ := h.publicPageService.GetLists(userModel.ID)
+
+	render(c, http.StatusOK, "settings.html", gin.H{
+		"title":                "Settings",
+		"user":                 userModel,
+		"languages":            i18n.Supported,
+		"regions":              regions,
+		"providers":            providers,
+		"selected":             selectedProviders(userModel),
+		"notificationTypes":    models.NotificationTypes,
+		"notificationChannels": models.NotificationChannels,
+		"preferences":          preferences,
+		"lists":                lists,
+		"shareURLs":            listShareURLs(c, userModel, lists),
+		"publicURL":            baseURL(c) + "/u/" + url.PathEscape(userModel.Username),
+	})
+}
+
+// GetProviders lists the watch providers of a region (?region=MX). HTMX
+// requests get checkboxes for the settings form.
+func (h *SettingsHandler) GetProviders(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	region := strings.ToUpper(c.Query("region"))
+	var providers []models.TMDBWatchProvider
+	if region != "" {
+		var err error
+		providers, err = h.providerService.GetProviders(region)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading streaming services"})
+			return
+		}
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "provider_options.html", gin.H{
+			"providers": providers,
+			"selected":  selectedProviders(userModel),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, providers)
+}
+
+func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	settings := services.UserSettings{
+		// Checkbox: sharing is on when checked, so an unchecked box opts out
+		TasteMatchingOptOut: negated(formCheckbox(c, "taste_matching")),
+		Language:            formString(c, "language"),
+		StreamingServices:   formInts(c, "providers"),
+		PrivateProfile:      formCheckbox(c, "private_profile"),
+		// Checkboxes: shown in feeds when checked
+		FeedHideAdded:   negated(formCheckbox(c, "feed_added")),
+		FeedHideWatched: negated(formCheckbox(c, "feed_watched")),
+		FeedHideRatings: negated(formCheckbox(c, "feed_ratings")),
+		PublicPage:      formCheckbox(c, "public_page"),
+		PublicRatings:   formCheckbox(c, "public_ratings"),
+		AllowIndexing:   formCheckbox(c, "allow_indexing"),
+	}
+
+	if region := formString(c, "region"); region != nil {
+		normalized := strings.ToUpper(strings.TrimSpace(*region))
+		settings.Region = &normalized
+	}
+
+	previousLanguage := requestLanguage(c)
+	previousPublicPage := userModel.PublicPage
+	if err := h.settingsService.UpdateSettings(userModel, settings); err != nil {
+		status, message := http.StatusInternalServerError, "Failed to save settings"
+		if errors.Is(err, services.ErrInvalidRegion) || errors.Is(err, services.ErrUnsupportedLanguage) {
+			status, message = http.StatusBadRequest, err.Error()
+		}
+		if c.GetHeader("HX-Request") == "true" {
+			render(c, http.StatusOK, "alert.html", gin.H{
+				"type":    "error",
+				"message": i18n.T(previousLanguage, message),
+			})
+			return
+		}
+		c.JSON(status, gin.H{"error": i18n.T(previousLanguage, message)})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		// Reload so the whole page switches to the new language, and the
+		// share links of public lists follow the public page
+		if requestLanguage(c) != previousLanguage || userModel.PublicPage != previousPublicPage {
+			c.Header("HX-Refresh", "true")
+		}
+		render(c, http.StatusOK, "alert.html", gin.H{
+			"type":    "success",
+			"message": i18n.T(requestLanguage(c), "Settings saved"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, userModel)
+}
+
+func selectedProviders(user *models.User) map[int]bool {
+	selected := make(map[int]bool, len(user.StreamingServices))
+	for _, providerID := range user.StreamingServices {
+		selected[providerID] = true
+	}
+	return selected
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"movie-tracker/models"
+	"movie-tracker/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShowHandler struct {
+	showService *services.ShowService
+	tmdbService *services.TMDBService
+}
+
+func NewShowHandler() *ShowHandler {
+	return &ShowHandler{
+		showService: services.NewShowService(),
+		tmdbService: services.NewTMDBService(),
+	}
+}
+
+// ShowShows renders the user's TV series with a search box to add more.
+func (h *ShowHandler) ShowShows(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	shows, err := h.showService.GetUserShows(userModel.ID)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading your shows",
+		})
+		return
+	}
+
+	render(c, http.StatusOK, "tv.html", gin.H{
+		"title": "TV Shows",
+		"user":  userModel,
+		"shows": shows,
+	})
+}
+
+func (h *ShowHandler) SearchShows(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter 'q' is required"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+
+	results, err := localTMDB(c, h.tmdbService).SearchShows(query, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	shows, err := h.showService.GetUserShows(userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading your shows"})
+		return
+	}
+	tracked := make(map[int]bool, len(shows))
+	for _, show := range shows {
+		tracked[show.TMDBId] = true
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "tv_search_results.html", gin.H{
+			"shows":   results.Results,
+			"tracked": tracked,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetShowDetail renders a TMDB series. If the user tracks it, its seasons
+// and episodes are listed with their watched state.
+func (h *ShowHandler) GetShowDetail(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	tmdbID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		render(c, http.StatusBadRequest, "error.html", gin.H{
+			"title": "Error",
+			"error": "Invalid show ID",
+		})
+		return
+	}
+
+	details, err := localTMDB(c, h.tmdbService).GetShowDetails(tmdbID)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading show details",
+		})
+		return
+	}
+
+	// Not tracking the show is not an error: the page offers to track it
+	show, seasons, _ := h.showService.GetShowByTMDBID(userModel.ID, tmdbID)
+
+	// Each season is rendered by show_season.html, which needs the language
+	seasonsData := make([]gin.H, 0, len(seasons))
+	for _, season := range seasons {
+		seasonsData = append(seasonsData, gin.H{"season": season, "lang": requestLanguage(c)})
+	}
+
+	render(c, http.StatusOK, "show_detail.html", gin.H{
+		"title":   details.Name,
+		"user":    userModel,
+		"details": details,
+		"show":    show,
+		"seasons": seasonsData,
+	})
+}
+
+func (h *ShowHandler) TrackShow(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	tmdbID, err := strconv.Atoi(c.PostForm("tmdb_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid TMDB ID"})
+		return
+	}
+
+	show, err := h.showService.WithLocale(tmdbLocale(c)).TrackShow(userModel.ID, tmdbID)
+	if err != nil {
+		if c.GetHeader("HX-Request") == "true" {
+			c.Header("HX-Reswap", "none")
+			render(c, http.StatusOK, "alert.html", gin.H{
+				"type":    "error",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Redirect", "/tv/"+strconv.Itoa(show.TMDBId))
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusCreated, show)
+}
+
+func (h *ShowHandler) DeleteShow(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := h.showService.DeleteShow(uint(id), userModel.ID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Redirect", "/tv")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Show removed successfully"})
+}
+
+// UpdateEpisode marks an episode as watched or not. HTMX requests get the
+// episode's season back and fire episodes-changed so the dashboard's next
+// episodes reload.
+func (h *ShowHandler) UpdateEpisode(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	episodeID, err := strconv.ParseUint(c.Param("episode_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid episode ID"})
+		return
+	}
+	watched := c.PostForm("watched") == "true"
+
+	episode, err := h.showService.SetEpisodeWatched(uint(id), userModel.ID, uint(episodeID), watched)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		h.respondWithSeason(c, uint(id), userModel.ID, episode.SeasonNumber)
+		return
+	}
+
+	c.JSON(http.StatusOK, episode)
+}
+
+// UpdateSeason marks every aired episode of a season as watched, or all of
+// them as unwatched.
+func (h *ShowHandler) UpdateSeason(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	seasonNumber, err := strconv.Atoi(c.Param("season"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season"})
+		return
+	}
+	watched := c.PostForm("watched") == "true"
+
+	if err := h.showService.SetSeasonWatched(uint(id), userModel.ID, seasonNumber, watched); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		h.respondWithSeason(c, uint(id), userModel.ID, seasonNumber)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Season updated successfully"})
+}
+
+// GetNextEpisodes lists the next episode to watch of each show in progress.
+func (h *ShowHandler) GetNextEpisodes(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "6"))
+	if limit <= 0 || limit > 40 {
+		limit = 6
+	}
+
+	next, err := h.showService.GetNextEpisodes(userModel.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading next episodes"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "next_episodes.html", gin.H{
+			"next": next,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, next)
+}
+
+func (h *ShowHandler) respondWithSeason(c *gin.Context, id, userID uint, seasonNumber int) {
+	season, err := h.showService.GetSeason(id, userID, seasonNumber)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("HX-Trigger", "episodes-changed")
+	render(c, http.StatusOK, "show_season.html", gin.H{"season": season})
+}
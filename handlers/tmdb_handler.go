@@ -1,21 +1,25 @@
 package handlers
 
 import (
+	"movie-tracker/models"
 	"movie-tracker/services"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type TMDBHandler struct {
-	tmdbService *services.TMDBService
+	tmdbService      *services.TMDBService
+	favoritesService *services.FavoritesService
+	followService    *services.FollowService
 }
 
 func NewTMDBHandler() *TMDBHandler {
 	return &TMDBHandler{
-		tmdbService: services.NewTMDBService(),
+		tmdbService:      services.NewTMDBService(),
+		favoritesService: services.NewFavoritesService(),
+		followService:    services.NewFollowService(),
 	}
 }
 
@@ -49,14 +53,14 @@ func (h *TMDBHandler) SearchMovies(c *gin.Context) {
 		page = 1
 	}
 
-	results, err := h.tmdbService.SearchMovies(query, page)
+	results, err := localTMDB(c, h.tmdbService).SearchMovies(query, page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	if c.GetHeader("HX-Request") == "true" {
-		c.HTML(http.StatusOK, "movie_card.html", gin.H{
+		render(c, http.StatusOK, "movie_card.html", gin.H{
 			"movies": results.Results,
 		})
 		return
@@ -72,14 +76,14 @@ func (h *TMDBHandler) GetPopularMovies(c *gin.Context) {
 		page = 1
 	}
 
-	results, err := h.tmdbService.GetPopularMovies(page)
+	results, err := localTMDB(c, h.tmdbService).GetPopularMovies(page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	if c.GetHeader("HX-Request") == "true" {
-		c.HTML(http.StatusOK, "movie_card.html", gin.H{
+		render(c, http.StatusOK, "movie_card.html", gin.H{
 			"movies": results.Results,
 		})
 		return
@@ -95,14 +99,14 @@ func (h *TMDBHandler) GetTrendingMovies(c *gin.Context) {
 		page = 1
 	}
 
-	results, err := h.tmdbService.GetTrendingMovies(page)
+	results, err := localTMDB(c, h.tmdbService).GetTrendingMovies(page)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	if c.GetHeader("HX-Request") == "true" {
-		c.HTML(http.StatusOK, "movie_card.html", gin.H{
+		render(c, http.StatusOK, "movie_card.html", gin.H{
 			"movies": results.Results,
 		})
 		return
@@ -111,31 +115,229 @@ func (h *TMDBHandler) GetTrendingMovies(c *gin.Context) {
 	c.JSON(http.StatusOK, results)
 }
 
+// DiscoverMovies browses TMDB with the filters of the search page sidebar
+// (genre, year_from, year_to, min_runtime, max_runtime, language, min_votes,
+// min_rating, sort). HTMX requests get the results and push the matching
+// search page URL so the filtered view can be shared.
+func (h *TMDBHandler) DiscoverMovies(c *gin.Context) {
+	filter, err := discoverFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+
+	results, err := localTMDB(c, h.tmdbService).DiscoverMovies(filter, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Push-Url", discoverURL(filter, results.Page))
+		render(c, http.StatusOK, "discover_results.html", discoverResultsData(filter, results))
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetSimilarMovies returns movies TMDB considers similar to :id. HTMX
+// requests get a carousel marked with the user's status for tracked titles.
+func (h *TMDBHandler) GetSimilarMovies(c *gin.Context) {
+	movieID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
+		return
+	}
+
+	results, err := localTMDB(c, h.tmdbService).GetSimilarMovies(movieID, 1)
+	h.respondWithRelated(c, results, err)
+}
+
+// GetMovieRecommendations returns TMDB's recommendations for :id.
+func (h *TMDBHandler) GetMovieRecommendations(c *gin.Context) {
+	movieID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid movie ID"})
+		return
+	}
+
+	results, err := localTMDB(c, h.tmdbService).GetMovieRecommendations(movieID, 1)
+	h.respondWithRelated(c, results, err)
+}
+
+func (h *TMDBHandler) respondWithRelated(c *gin.Context, results *models.TMDBResponse, err error) {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	tmdbIDs := make([]int, 0, len(results.Results))
+	for _, movie := range results.Results {
+		tmdbIDs = append(tmdbIDs, movie.ID)
+	}
+	statuses, err := h.favoritesService.GetStatusesByTMDBIDs(userModel.ID, tmdbIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading your statuses"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "related_movies.html", gin.H{
+			"movies":   results.Results,
+			"statuses": statuses,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":  results.Results,
+		"statuses": statuses,
+	})
+}
+
 func (h *TMDBHandler) GetMovieDetail(c *gin.Context) {
 	movieIDStr := c.Param("id")
 	movieID, err := strconv.Atoi(movieIDStr)
 	if err != nil {
-		c.HTML(http.StatusBadRequest, "error.html", gin.H{
+		render(c, http.StatusBadRequest, "error.html", gin.H{
 			"title": "Error",
 			"error": "Invalid movie ID",
 		})
 		return
 	}
 
-	movieDetail, err := h.tmdbService.GetMovieFullDetails(movieID)
+	movieDetail, err := localTMDB(c, h.tmdbService).GetMovieFullDetails(movieID)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading movie details",
+		})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	connections, err := h.followService.Connections(userModel.ID)
 	if err != nil {
-		c.HTML(http.StatusInternalServerError, "error.html", gin.H{
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
 			"title": "Error",
 			"error": "Error loading movie details",
 		})
 		return
 	}
 
-	c.HTML(http.StatusOK, "movie_detail.html", gin.H{
-		"title":          movieDetail.Title,
-		"movie":          movieDetail,
-		"user":           c.MustGet("user"),
-		"formatBudget":   formatNumber(movieDetail.Budget),
-		"formatRevenue":  formatNumber(movieDetail.Revenue),
+	render(c, http.StatusOK, "movie_detail.html", gin.H{
+		"title":         movieDetail.Title,
+		"movie":         movieDetail,
+		"user":          userModel,
+		"connections":   connections,
+		"formatBudget":  formatNumber(movieDetail.Budget),
+		"formatRevenue": formatNumber(movieDetail.Revenue),
+	})
+}
+
+// GetPersonDetail renders an actor's or crew member's page with their
+// filmography, marking the movies the user already tracks.
+func (h *TMDBHandler) GetPersonDetail(c *gin.Context) {
+	personID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		render(c, http.StatusBadRequest, "error.html", gin.H{
+			"title": "Error",
+			"error": "Invalid person ID",
+		})
+		return
+	}
+
+	person, err := localTMDB(c, h.tmdbService).GetPersonDetails(personID)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading person details",
+		})
+		return
+	}
+
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	filmography := person.Filmography()
+	tmdbIDs := make([]int, 0, len(filmography))
+	for _, entry := range filmography {
+		tmdbIDs = append(tmdbIDs, entry.Movie.ID)
+	}
+	statuses, err := h.favoritesService.GetStatusesByTMDBIDs(userModel.ID, tmdbIDs)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading your statuses",
+		})
+		return
+	}
+
+	render(c, http.StatusOK, "person_detail.html", gin.H{
+		"title":       person.Name,
+		"person":      person,
+		"filmography": filmography,
+		"statuses":    statuses,
+		"tracked":     len(statuses),
+		"user":        userModel,
 	})
+}
+
+func discoverFilterFromQuery(c *gin.Context) (services.DiscoverFilter, error) {
+	var query formParser
+	filter := services.DiscoverFilter{
+		GenreIDs:       intValues(c.QueryArray("genre")),
+		YearFrom:       query.optionalInt("year_from", c.Query("year_from")),
+		YearTo:         query.optionalInt("year_to", c.Query("year_to")),
+		MinRuntime:     query.optionalInt("min_runtime", c.Query("min_runtime")),
+		MaxRuntime:     query.optionalInt("max_runtime", c.Query("max_runtime")),
+		Language:       c.Query("language"),
+		MinVoteCount:   query.optionalInt("min_votes", c.Query("min_votes")),
+		MinVoteAverage: query.optionalFloat("min_rating", c.Query("min_rating")),
+		SortBy:         c.Query("sort"),
+	}
+	return filter, query.err
+}
+
+// discoverURL is the search page showing page of filter's results.
+func discoverURL(filter services.DiscoverFilter, page int) string {
+	values := filter.Values()
+	if page > 1 {
+		values.Set("page", strconv.Itoa(page))
+	}
+	if len(values) == 0 {
+		return "/search"
+	}
+	return "/search?" + values.Encode()
+}
+
+// discoverResultsData is what discover_results.html needs to show results
+// with links to the previous and next pages.
+func discoverResultsData(filter services.DiscoverFilter, results *models.TMDBResponse) gin.H {
+	totalPages := results.TotalPages
+	if totalPages > services.DiscoverMaxPage {
+		totalPages = services.DiscoverMaxPage
+	}
+
+	data := gin.H{
+		"movies":       results.Results,
+		"page":         results.Page,
+		"totalPages":   totalPages,
+		"totalResults": formatNumber(int64(results.TotalResults)),
+		"query":        filter.Values().Encode(),
+	}
+	if results.Page > 1 {
+		data["prevPage"] = results.Page - 1
+	}
+	if results.Page < totalPages {
+		data["nextPage"] = results.Page + 1
+	}
+	return data
 }
\ No newline at end of file
@@ -4,6 +4,7 @@ import (
 	"movie-tracker/models"
 	"movie-tracker/services"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,4 +30,35 @@ func (h *UserHandler) GetStats(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, stats)
-}
\ No newline at end of file
+}
+
+func (h *UserHandler) GetGenreStats(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	stats, err := h.favoritesService.GetGenreStats(userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get genre stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *UserHandler) GetDirectorStats(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if limit <= 0 || limit > 40 {
+		limit = 5
+	}
+
+	stats, err := h.favoritesService.GetDirectorStats(userModel.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get director stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"movie-tracker/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// deliveryLogSize is how many deliveries the log of a webhook shows.
+const deliveryLogSize = 20
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+func NewWebhookHandler() *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: services.NewWebhookService(),
+	}
+}
+
+// ShowWebhooks renders the user's webhooks. Each one loads its delivery log
+// separately.
+func (h *WebhookHandler) ShowWebhooks(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	webhooks, err := h.webhookService.GetWebhooks(userModel.ID)
+	if err != nil {
+		render(c, http.StatusInternalServerError, "error.html", gin.H{
+			"title": "Error",
+			"error": "Error loading your webhooks",
+		})
+		return
+	}
+
+	render(c, http.StatusOK, "webhooks.html", gin.H{
+		"title":       "Webhooks",
+		"user":        userModel,
+		"webhooks":    webhooks,
+		"events":      services.WebhookEvents,
+		"maxWebhooks": services.MaxWebhooksPerUser,
+	})
+}
+
+// ListWebhooks returns the user's webhooks.
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	webhooks, err := h.webhookService.GetWebhooks(userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error loading webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhook registers a webhook URL for the user.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	var req struct {
+		URL string `json:"url" form:"url" binding:"required"`
+	}
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(userModel.ID, strings.TrimSpace(req.URL))
+	if err != nil {
+		if c.GetHeader("HX-Request") == "true" {
+			render(c, http.StatusOK, "alert.html", gin.H{
+				"type":    "error",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusCreated)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// DeleteWebhook removes one of the user's webhooks.
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(uint(id), userModel.ID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		c.Header("HX-Refresh", "true")
+		c.Status(http.StatusOK)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// SendTest sends a sample event to one of the user's webhooks and reports
+// how the receiver answered. HTMX requests also get the delivery log
+// reloaded.
+func (h *WebhookHandler) SendTest(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	delivery, err := h.webhookService.SendTest(uint(id), userModel.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		lang := requestLanguage(c)
+		data := gin.H{
+			"type":    "success",
+			"message": i18n.T(lang, "Test event delivered"),
+		}
+		if delivery.Status != models.DeliveryDelivered {
+			data["type"] = "error"
+			data["message"] = i18n.T(lang, "Test event failed: %s", delivery.Error)
+		}
+		c.Header("HX-Trigger", "webhookDelivered")
+		render(c, http.StatusOK, "alert.html", data)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// ListDeliveries returns the recent deliveries of one of the user's
+// webhooks. HTMX requests get the delivery log table.
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	user, _ := c.Get("user")
+	userModel := user.(*models.User)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(uint(id), userModel.ID, deliveryLogSize)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		render(c, http.StatusOK, "webhook_deliveries.html", gin.H{
+			"deliveries": deliveries,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
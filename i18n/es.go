@@ -0,0 +1,452 @@
+package i18n
+
+// spanish translates UI messages, keyed by their English text, into Spanish.
+var spanish = map[string]string{
+	// Navigation
+	"Search":    "Buscar",
+	"Favorites": "Favoritas",
+	"TV":        "Series",
+	"Settings":  "Ajustes",
+	"Logout":    "Cerrar sesión",
+	"Hello, %s": "Hola, %s",
+	"Calendar":  "Calendario",
+
+	// Statuses, priorities and sort orders
+	"To Watch":         "Por ver",
+	"Watching":         "Viendo",
+	"Watched":          "Vista",
+	"Recommended":      "Recomendada",
+	"Abandoned":        "Abandonada",
+	"Normal":           "Normal",
+	"High":             "Alta",
+	"Must watch":       "Imperdible",
+	"Most popular":     "Más populares",
+	"Highest rated":    "Mejor valoradas",
+	"Most voted":       "Más votadas",
+	"Newest":           "Más recientes",
+	"Oldest":           "Más antiguas",
+	"Highest grossing": "Más taquilleras",
+
+	// Dashboard
+	"Welcome back, %s!": "¡Bienvenido de nuevo, %s!",
+	"Manage your movie collection and discover new films": "Administra tu colección de películas y descubre nuevas",
+	"Quick Actions":           "Accesos rápidos",
+	"Search Movies":           "Buscar películas",
+	"View All Favorites":      "Ver todas las favoritas",
+	"Your Stats":              "Tus estadísticas",
+	"Total Movies:":           "Total de películas:",
+	"Hours Watched:":          "Horas vistas:",
+	"Top Genres:":             "Géneros favoritos:",
+	"Most Watched Directors:": "Directores más vistos:",
+
+	// Favorites and trash
+	"Your Favorites": "Tus favoritas",
+	"Trash":          "Papelera",
+	"Add Movies":     "Agregar películas",
+	"All":            "Todas",
+	"All genres":     "Todos los géneros",
+	"Search your collection (title, notes, who recommended it...)": "Busca en tu colección (título, notas, quién la recomendó...)",
+	"Stream on":          "Disponible en",
+	"The trash is empty": "La papelera está vacía",
+
+	// Search
+	"Discover":             "Descubrir",
+	"Search for movies...": "Busca películas...",
+	"Popular Movies":       "Películas populares",
+	"Add to Favorites":     "Agregar a favoritas",
+
+	// Movie details
+	"Overview":               "Sinopsis",
+	"Genres":                 "Géneros",
+	"Crew":                   "Equipo",
+	"Directed by":            "Dirigida por",
+	"Written by":             "Escrita por",
+	"Cast":                   "Reparto",
+	"Production Details":     "Detalles de producción",
+	"Budget":                 "Presupuesto",
+	"Revenue":                "Recaudación",
+	"Original Language":      "Idioma original",
+	"Popularity":             "Popularidad",
+	"Production Companies":   "Productoras",
+	"Additional Information": "Información adicional",
+	"Spoken Languages":       "Idiomas hablados",
+	"Production Countries":   "Países de producción",
+
+	// TV
+	"TV Shows":                      "Series",
+	"Your Shows":                    "Tus series",
+	"Search for a series to add...": "Busca una serie para agregar...",
+
+	// Settings
+	"Choose how Movie Tracker uses your data.": "Elige cómo usa Movie Tracker tus datos.",
+	"Language": "Idioma",
+	"The language of the interface and of movie titles, overviews and genres.": "El idioma de la interfaz y de los títulos, sinopsis y géneros de las películas.",
+	"Automatic (browser)": "Automático (navegador)",
+	"Similar taste":       "Gustos similares",
+	"Where you watch":     "Dónde ves",
+	"Country":             "País",
+	"Not set":             "Sin definir",
+	"Save settings":       "Guardar ajustes",
+
+	// Release calendar
+	"Release calendar": "Calendario de estrenos",
+	"Upcoming theatrical, digital and physical releases of the movies on your watchlist.": "Próximos estrenos en cines, digitales y físicos de las películas de tu lista por ver.",
+	"Only primary release dates are shown.":                                               "Solo se muestran las fechas de estreno principales.",
+	"Set your country in Settings":                                                        "Elige tu país en Ajustes",
+	"to see the dates where you live.":                                                    "para ver las fechas donde vives.",
+	"Private calendar feed":                                                               "Calendario privado",
+	"Subscribe":                                                                           "Suscribirse",
+	"Reset link":                                                                          "Cambiar enlace",
+	"Create a new link? Calendars subscribed to the current one will stop updating.":                                                "¿Crear un enlace nuevo? Los calendarios suscritos al actual dejarán de actualizarse.",
+	"Anyone with this link can see your upcoming releases. Add it to Google Calendar, Apple Calendar or Outlook to get them there.": "Cualquiera con este enlace puede ver tus próximos estrenos. Agrégalo a Google Calendar, Apple Calendar u Outlook para verlos ahí.",
+	"Nothing coming up": "Nada próximamente",
+	"Unreleased movies you add to your watchlist will show up here.": "Las películas sin estrenar que agregues a tu lista por ver aparecerán aquí.",
+	"Movie Tracker releases": "Estrenos de Movie Tracker",
+	"Release":                "Estreno",
+	"Premiere":               "Premier",
+	"Limited theatrical":     "Estreno limitado en cines",
+	"Theatrical":             "Cines",
+	"Digital":                "Digital",
+	"Physical":               "Físico",
+	"January":                "Enero",
+	"February":               "Febrero",
+	"March":                  "Marzo",
+	"April":                  "Abril",
+	"May":                    "Mayo",
+	"June":                   "Junio",
+	"July":                   "Julio",
+	"August":                 "Agosto",
+	"September":              "Septiembre",
+	"October":                "Octubre",
+	"November":               "Noviembre",
+	"December":               "Diciembre",
+	"Mon":                    "Lun",
+	"Tue":                    "Mar",
+	"Wed":                    "Mié",
+	"Thu":                    "Jue",
+	"Fri":                    "Vie",
+	"Sat":                    "Sáb",
+	"Sun":                    "Dom",
+
+	// Notifications
+	"Notifications":                    "Notificaciones",
+	"Watchlist movie released":         "Estreno de tu lista por ver",
+	"Now streaming on your services":   "Ya disponible en tus servicios",
+	"Recommended to you":               "Te recomendaron",
+	"In-app":                           "En la app",
+	"Email":                            "Correo",
+	"Webhook":                          "Webhook",
+	"%s is out today":                  "%s se estrena hoy",
+	"%s is now streaming on %s":        "%s ya está disponible en %s",
+	"Here is what happened this week:": "Esto pasó esta semana:",
+	"Your week in Movie Tracker":       "Tu semana en Movie Tracker",
+	"Mark all as read":                 "Marcar todas como leídas",
+	"No notifications yet":             "Aún no tienes notificaciones",
+	"Choose what you are notified about, and how, in":                                                 "Elige de qué te avisamos, y cómo, en",
+	"You'll hear here when a movie on your watchlist comes out or starts streaming on your services.": "Aquí te avisaremos cuando una película de tu lista por ver se estrene o llegue a tus servicios.",
+	"Choose how you hear about each kind of news.":                                                    "Elige cómo te enteras de cada tipo de novedad.",
+	"Webhook URL": "URL del webhook",
+	"Notifications on the webhook channel are POSTed here as JSON.": "Las notificaciones del canal webhook se envían aquí como JSON con POST.",
+	"Email me a weekly digest of my notifications":                  "Enviarme por correo un resumen semanal de mis notificaciones",
+	"Save notification preferences":                                 "Guardar preferencias de notificaciones",
+	"Notification preferences saved":                                "Preferencias de notificaciones guardadas",
+	"Failed to save notification preferences":                       "No se pudieron guardar las preferencias de notificaciones",
+
+	// Webhooks
+	"Webhooks": "Webhooks",
+	"Every change to your favorites is POSTed as JSON to your webhooks:": "Cada cambio en tus favoritas se envía como JSON con POST a tus webhooks:",
+	"The X-Movie-Tracker-Signature header holds sha256= followed by the hex HMAC-SHA256 of the body, keyed with the webhook's secret. Failed deliveries are retried with exponential backoff.": "La cabecera X-Movie-Tracker-Signature contiene sha256= seguido del HMAC-SHA256 en hexadecimal del cuerpo, con el secreto del webhook como clave. Los envíos fallidos se reintentan con espera exponencial.",
+	"Add webhook":                           "Agregar webhook",
+	"You can register at most %d webhooks.": "Puedes registrar como máximo %d webhooks.",
+	"Show secret":                           "Mostrar secreto",
+	"Send test event":                       "Enviar evento de prueba",
+	"Delete":                                "Eliminar",
+	"Delete this webhook and its delivery log?": "¿Eliminar este webhook y su registro de envíos?",
+	"Recent deliveries":                         "Envíos recientes",
+	"Loading...":                                "Cargando...",
+	"No webhooks yet":                           "Todavía no hay webhooks",
+	"Add a URL above to receive your favorites events.": "Agrega una URL arriba para recibir los eventos de tus favoritas.",
+	"Event":         "Evento",
+	"Status":        "Estado",
+	"Response":      "Respuesta",
+	"Attempts":      "Intentos",
+	"Sent":          "Enviado",
+	"Delivered":     "Entregado",
+	"Failed":        "Fallido",
+	"Retrying":      "Reintentando",
+	"Response body": "Cuerpo de la respuesta",
+	"Nothing has been sent to this webhook yet.":                               "Todavía no se ha enviado nada a este webhook.",
+	"Send every change to your favorites to your own services as signed JSON.": "Envía cada cambio en tus favoritas a tus propios servicios como JSON firmado.",
+	"Manage webhooks":       "Gestionar webhooks",
+	"Test event delivered":  "Evento de prueba entregado",
+	"Test event failed: %s": "El evento de prueba falló: %s",
+
+	// People and feed
+	"People": "Personas",
+	"Follow other users to see what they add, watch and rate on your dashboard.": "Sigue a otros usuarios para ver en tu panel lo que agregan, ven y califican.",
+	"Your profile is private: you approve who follows you.":                      "Tu perfil es privado: tú apruebas quién te sigue.",
+	"Privacy settings":             "Ajustes de privacidad",
+	"Search by username...":        "Buscar por nombre de usuario...",
+	"Follow requests":              "Solicitudes de seguimiento",
+	"Accept":                       "Aceptar",
+	"Decline":                      "Rechazar",
+	"Following":                    "Siguiendo",
+	"Followers":                    "Seguidores",
+	"Requested":                    "Solicitado",
+	"Cancel request":               "Cancelar solicitud",
+	"Unfollow":                     "Dejar de seguir",
+	"You don't follow anyone yet.": "Todavía no sigues a nadie.",
+	"Remove this follower? They stop seeing your activity.": "¿Quitar a este seguidor? Dejará de ver tu actividad.",
+	"Remove":                  "Quitar",
+	"Nobody follows you yet.": "Todavía nadie te sigue.",
+	"Follow":                  "Seguir",
+	"Request to follow":       "Solicitar seguir",
+	"No users match “%s”.":    "Ningún usuario coincide con “%s”.",
+	"Friends' activity":       "Actividad de tus amigos",
+	"added":                   "agregó",
+	"watched":                 "vio",
+	"rated":                   "calificó",
+	"Nothing here yet.":       "Aún no hay nada aquí.",
+	"Follow people to see what they add, watch and rate.": "Sigue a otras personas para ver lo que agregan, ven y califican.",
+	"Privacy":         "Privacidad",
+	"Private profile": "Perfil privado",
+	"Approve each follow request before someone sees your activity. Turning this off accepts the pending requests.": "Aprueba cada solicitud antes de que alguien vea tu actividad. Al desactivarlo se aceptan las solicitudes pendientes.",
+	"Show in your followers' feeds:":  "Mostrar en la actividad de tus seguidores:",
+	"Movies I add":                    "Películas que agrego",
+	"Movies I watch":                  "Películas que veo",
+	"My ratings":                      "Mis calificaciones",
+	"Followers and follow requests":   "Seguidores y solicitudes de seguimiento",
+	"%s started following you":        "%s empezó a seguirte",
+	"%s wants to follow you":          "%s quiere seguirte",
+	"%s accepted your follow request": "%s aceptó tu solicitud de seguimiento",
+
+	// Recommendations between users
+	"Recommend to…":             "Recomendar a…",
+	"Recommend %s":              "Recomendar %s",
+	"To":                        "Para",
+	"Message":                   "Mensaje",
+	"Why should they watch it?": "¿Por qué debería verla?",
+	"Cancel":                    "Cancelar",
+	"Send":                      "Enviar",
+	"Close":                     "Cerrar",
+	"You can recommend movies to the people you follow or who follow you.": "Puedes recomendar películas a las personas que sigues o que te siguen.",
+	"Find people":          "Buscar personas",
+	"Recommendation sent!": "¡Recomendación enviada!",
+	"%s recommends you %s": "%s te recomienda %s",
+	"%s recommends you":    "%s te recomienda",
+	"Movies the people you follow or who follow you think you should watch. Accepting one adds it to your favorites as recommended.": "Películas que las personas que sigues o que te siguen creen que deberías ver. Al aceptar una se agrega a tus favoritas como recomendada.",
+	"Nobody has recommended you a movie yet.": "Todavía nadie te ha recomendado una película.",
+	"Accepted":                "Aceptada",
+	"Declined":                "Rechazada",
+	"Pending":                 "Pendiente",
+	"Your recommendations":    "Tus recomendaciones",
+	"to %s":                   "para %s",
+	"Removed from their list": "Quitada de su lista",
+	"Recommend a movie from its page to the people you follow or who follow you.": "Recomienda una película desde su página a las personas que sigues o que te siguen.",
+
+	// Public pages
+	"Public page": "Página pública",
+	"Share a public page with your public lists":       "Compartir una página pública con tus listas públicas",
+	"Anyone can see it without logging in, at":         "Cualquiera puede verla sin iniciar sesión, en",
+	"Show my ratings on it":                            "Mostrar mis calificaciones en ella",
+	"Let search engines show my public page and lists": "Permitir que los buscadores muestren mi página pública y mis listas",
+	"Lists shared by link are never indexed.":          "Las listas compartidas por enlace nunca se indexan.",
+	"Sharing your lists":                               "Compartir tus listas",
+	"Private lists are only yours. Unlisted ones can be seen by anyone with their link, and public ones also show on your public page.": "Las listas privadas son solo tuyas. Las no listadas las puede ver cualquiera con su enlace, y las públicas también aparecen en tu página pública.",
+	"Private":  "Privada",
+	"Unlisted": "No listada",
+	"Public":   "Pública",
+	"Create a new link? The current one will stop working.":                                   "¿Crear un enlace nuevo? El actual dejará de funcionar.",
+	"Turn on your public page to show this list there; until then it is shared by link only.": "Activa tu página pública para mostrar esta lista en ella; mientras tanto solo se comparte por enlace.",
+	"You have no lists yet. Add movies to a list from your favorites to share it.":            "Todavía no tienes listas. Agrega películas a una lista desde tus favoritas para compartirla.",
+	"%s on Movie Tracker":                  "%s en Movie Tracker",
+	"Movie lists shared by %s.":            "Listas de películas compartidas por %s.",
+	"Movies rated and lists shared by %s.": "Películas calificadas y listas compartidas por %s.",
+	"%s · a list by %s":                    "%s · una lista de %s",
+	"A movie list by %s.":                  "Una lista de películas de %s.",
+	"Track your own movies":                "Lleva el registro de tus películas",
+	"Lists":                                "Listas",
+	"Movies: %d":                           "Películas: %d",
+	"No public lists yet.":                 "Aún no hay listas públicas.",
+	"Ratings":                              "Calificaciones",
+	"No ratings yet.":                      "Aún no hay calificaciones.",
+	"A list by %s":                         "Una lista de %s",
+	"A list by":                            "Una lista de",
+	"This list is empty.":                  "Esta lista está vacía.",
+
+	// Alerts
+	"Undo":                                   "Deshacer",
+	"Movie added to favorites!":              "¡Película agregada a favoritas!",
+	"Movie moved to trash":                   "Película enviada a la papelera",
+	"%s is now at the top of your watchlist": "%s está ahora al principio de tu lista por ver",
+	"Failed to save settings":                "No se pudieron guardar los ajustes",
+	"Settings saved":                         "Ajustes guardados",
+	"invalid region":                         "Región no válida",
+	"unsupported language":                   "Idioma no disponible",
+
+	// Login and registration
+	"Login":                                        "Iniciar sesión",
+	"Register":                                     "Registrarse",
+	"Sign in to your account":                      "Inicia sesión en tu cuenta",
+	"Create your account":                          "Crea tu cuenta",
+	"Username or Email":                            "Usuario o correo",
+	"Username":                                     "Usuario",
+	"Password":                                     "Contraseña",
+	"Password (min 8 characters)":                  "Contraseña (mínimo 8 caracteres)",
+	"Sign In":                                      "Iniciar sesión",
+	"Don't have an account? Register here":         "¿No tienes cuenta? Regístrate aquí",
+	"Already have an account? Sign in":             "¿Ya tienes cuenta? Inicia sesión",
+	"username or email already exists":             "el usuario o el correo ya existen",
+	"username and password are required":           "el usuario y la contraseña son obligatorios",
+	"invalid credentials":                          "credenciales inválidas",
+	"username must be between 3 and 50 characters": "el usuario debe tener entre 3 y 50 caracteres",
+	"password must be at least 8 characters long":  "la contraseña debe tener al menos 8 caracteres",
+	"invalid email format":                         "formato de correo inválido",
+
+	// Error pages
+	"Error":                                   "Error",
+	"Not Found":                               "No encontrada",
+	"Go Back":                                 "Volver",
+	"Error loading movie details":             "Error al cargar los detalles de la película",
+	"Error loading person details":            "Error al cargar los detalles de la persona",
+	"Error loading show details":              "Error al cargar los detalles de la serie",
+	"Error loading the people you follow":     "Error al cargar las personas que sigues",
+	"Error loading this page":                 "Error al cargar esta página",
+	"Error loading your follow requests":      "Error al cargar tus solicitudes de seguimiento",
+	"Error loading your followers":            "Error al cargar tus seguidores",
+	"Error loading your notifications":        "Error al cargar tus notificaciones",
+	"Error loading your recommendations":      "Error al cargar tus recomendaciones",
+	"Error loading your release calendar":     "Error al cargar tu calendario de estrenos",
+	"Error loading your shows":                "Error al cargar tus series",
+	"Error loading your statuses":             "Error al cargar tus estados",
+	"Error loading your webhooks":             "Error al cargar tus webhooks",
+	"Invalid movie ID":                        "ID de película inválido",
+	"Invalid person ID":                       "ID de persona inválido",
+	"Invalid show ID":                         "ID de serie inválido",
+	"This page doesn't exist or isn't shared": "Esta página no existe o no está compartida",
+
+	// Dashboard sections
+	"Dashboard":                         "Panel",
+	"View all":                          "Ver todas",
+	"Keep tracking your movie journey!": "¡Sigue registrando tu recorrido por el cine!",
+	"Next Up":                           "A continuación",
+	"Loading your shows...":             "Cargando tus series...",
+	"For You":                           "Para ti",
+	"Finding movies for you...":         "Buscando películas para ti...",
+	"People With Similar Taste Loved…":  "A quienes tienen gustos similares les encantaron…",
+	"Looking for kindred spirits...":    "Buscando almas gemelas...",
+	"Loading popular movies...":         "Cargando películas populares...",
+	"movies":                            "películas",
+	"over":                              "en",
+	"episodes":                          "episodios",
+
+	// Picker
+	"What should I watch tonight?": "¿Qué veo esta noche?",
+	"Time available":               "Tiempo disponible",
+	"Any length":                   "Cualquier duración",
+	"Up to 1h30":                   "Hasta 1h30",
+	"Up to 2h":                     "Hasta 2h",
+	"Up to 2h30":                   "Hasta 2h30",
+	"Not in the mood for":          "Sin ganas de",
+	"Mood":                         "Estado de ánimo",
+	"Any":                          "Cualquiera",
+	"Decade":                       "Década",
+	"%d0s":                         "Años %d0",
+	"Anyone":                       "Cualquiera",
+	"Pick a movie":                 "Elegir una película",
+	"Watch it":                     "Verla",
+	"Not tonight":                  "Hoy no",
+	"Pick another":                 "Elegir otra",
+	"Chosen from %d movie":         "Elegida entre %d película",
+	"Chosen from %d movies":        "Elegida entre %d películas",
+	"%s %s priority":               "%s Prioridad: %s",
+	"On your list for a month":     "En tu lista desde hace un mes",
+	"On your list for %d months":   "En tu lista desde hace %d meses",
+	"Rated %.1f on TMDB":           "Calificada con %.1f en TMDB",
+	"Recommended by %s":            "Recomendada por %s",
+	"no movie on your watchlist matches tonight's constraints": "ninguna película de tu lista por ver cumple las condiciones de esta noche",
+
+	// Goals
+	"Goals":                  "Metas",
+	"Loading goals...":       "Cargando metas...",
+	"New goal":               "Nueva meta",
+	"Title":                  "Título",
+	"52 films in 2027":       "52 películas en 2027",
+	"Target (films)":         "Objetivo (películas)",
+	"Genre":                  "Género",
+	"From":                   "Desde",
+	"Until":                  "Hasta",
+	"Released before (year)": "Estrenada antes de (año)",
+	"Original language":      "Idioma original",
+	"Min runtime":            "Duración mínima",
+	"Max runtime":            "Duración máxima",
+	"Create Goal":            "Crear meta",
+	"Delete this goal?":      "¿Eliminar esta meta?",
+	"%d / %d films":          "%d / %d películas",
+	"No goals yet. Set one below to start tracking your progress.": "Aún no tienes metas. Crea una abajo para empezar a seguir tu progreso.",
+	"Goal reached 🎉":       "Meta cumplida 🎉",
+	"%s to go":             "Faltan %s",
+	"Starts %s":            "Empieza el %s",
+	"Ended %s short":       "Terminó con %s de menos",
+	"%s ahead of schedule": "%s por delante del plan",
+	"%s behind schedule":   "%s por detrás del plan",
+	"On schedule":          "Al día con el plan",
+	"1 film":               "1 película",
+	"%d films":             "%d películas",
+
+	// Recommendations
+	"Watchlist": "Por ver",
+	"Rate a few movies you've watched and we'll suggest what to see next.": "Califica algunas películas que hayas visto y te sugeriremos qué ver después.",
+	"Because %s":                                "Porque %s",
+	"%s and %s":                                 "%s y %s",
+	"you rate %s highly":                        "calificas alto %s",
+	"you enjoy films from the %ds":              "disfrutas las películas de los años %d",
+	"you enjoy films in %s":                     "disfrutas las películas en %s",
+	"it's from %s, whose films you rate highly": "es de %s, cuyas películas calificas alto",
+	"it's similar to %s, which you rated %d/10": "se parece a %s, que calificaste con %d/10",
+	"Trending this week":                        "Tendencia esta semana",
+	"Popular right now":                         "Popular ahora mismo",
+	"Similar-taste suggestions are turned off. You can turn them on in":                                                                 "Las sugerencias por gustos similares están desactivadas. Puedes activarlas en",
+	"No matches yet. Keep rating movies — once you and other users have rated enough of the same films, their favourites show up here.": "Aún no hay coincidencias. Sigue calificando películas: cuando tú y otros usuarios hayan calificado suficientes películas en común, sus favoritas aparecerán aquí.",
+	"Loved by someone with similar taste (%.0f/10)":                                                                                     "Le encantó a alguien con gustos similares (%.0f/10)",
+	"Loved by %d people with similar taste (avg %.1f/10)":                                                                               "Les encantó a %d personas con gustos similares (promedio %.1f/10)",
+	"You watched":            "Viste",
+	"How would you rate it?": "¿Cómo la calificarías?",
+
+	// Movie cards and discover results
+	"Details":                          "Detalles",
+	"Add":                              "Agregar",
+	"Put at the top of your watchlist": "Poner al principio de tu lista por ver",
+	"No movies found. Try a different search term.": "No se encontraron películas. Prueba con otra búsqueda.",
+	"%s movies match your filters":                  "%s películas coinciden con tus filtros",
+	"Previous":                                      "Anterior",
+	"Next":                                          "Siguiente",
+	"Page %d of %d":                                 "Página %d de %d",
+	"No movies match these filters. Try widening them.":                  "Ninguna película coincide con estos filtros. Prueba a ampliarlos.",
+	"Choose your country to see the streaming services available there.": "Elige tu país para ver los servicios de streaming disponibles allí.",
+	"Added %s":   "Agregada el %s",
+	"Deleted %s": "Eliminada el %s",
+
+	// History
+	"Rating":                   "Calificación",
+	"Notes":                    "Notas",
+	"Recommended by":           "Recomendada por",
+	"Watched on":               "Vista el",
+	"Abandoned because":        "Abandonada porque",
+	"In the trash since":       "En la papelera desde",
+	"No changes recorded yet.": "Aún no hay cambios registrados.",
+
+	// Series
+	"Tracking":               "Siguiendo",
+	"Track":                  "Seguir",
+	"No series found.":       "No se encontraron series.",
+	"%d aired episode left":  "Queda %d episodio emitido",
+	"%d aired episodes left": "Quedan %d episodios emitidos",
+	"Nothing to catch up on. Start a series from the": "Estás al día. Empieza una serie desde la",
+	"TV page":                     "página de series",
+	"Season %d":                   "Temporada %d",
+	"%d / %d watched":             "%d / %d vistos",
+	"Mark season unwatched":       "Marcar temporada como no vista",
+	"Mark aired episodes watched": "Marcar episodios emitidos como vistos",
+	"TBA":                         "Por anunciar",
+}
@@ -0,0 +1,115 @@
+// Package i18n translates the UI and picks the language of each request.
+// Messages are keyed by their English text, so a missing translation falls
+// back to English.
+package i18n
+
+import (
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// Default is the language used when nothing better matches.
+const Default = "en"
+
+// Language is a UI language users can choose.
+type Language struct {
+	Code string `json:"code"`
+	Name string `json:"name"` // In the language itself
+	// TMDB is the locale TMDB content is requested in. It matches the
+	// languages genres are synced in (GENRE_LANGUAGES).
+	TMDB string `json:"tmdb"`
+	// DateLayout formats dates, as time.Format
+	DateLayout string `json:"date_layout"`
+}
+
+// Supported lists the available languages, Default first.
+var Supported = []Language{
+	{Code: "en", Name: "English", TMDB: "en-US", DateLayout: "Jan 2, 2006"},
+	{Code: "es", Name: "Español", TMDB: "es-MX", DateLayout: "02/01/2006"},
+}
+
+var (
+	matcher  language.Matcher
+	printers = make(map[string]*message.Printer)
+)
+
+func init() {
+	builder := catalog.NewBuilder(catalog.Fallback(language.English))
+	for key, msg := range spanish {
+		if err := builder.SetString(language.Spanish, key, msg); err != nil {
+			panic(err)
+		}
+	}
+
+	tags := make([]language.Tag, 0, len(Supported))
+	for _, lang := range Supported {
+		tag := language.MustParse(lang.Code)
+		tags = append(tags, tag)
+		printers[lang.Code] = message.NewPrinter(tag, message.Catalog(builder))
+	}
+	matcher = language.NewMatcher(tags)
+}
+
+// IsSupported reports whether code is one of the Supported languages.
+func IsSupported(code string) bool {
+	_, ok := printers[code]
+	return ok
+}
+
+// Match returns the supported language that best fits an Accept-Language
+// header, or Default.
+func Match(acceptLanguage string) string {
+	_, index := language.MatchStrings(matcher, acceptLanguage)
+	return Supported[index].Code
+}
+
+// Resolve returns the language to use for a user: their saved choice if it
+// is supported, otherwise the best match for acceptLanguage.
+func Resolve(userLanguage, acceptLanguage string) string {
+	if IsSupported(userLanguage) {
+		return userLanguage
+	}
+	return Match(acceptLanguage)
+}
+
+// T translates key into lang, formatting args into it like fmt.Sprintf.
+func T(lang, key string, args ...interface{}) string {
+	printer, ok := printers[lang]
+	if !ok {
+		printer = printers[Default]
+	}
+	return printer.Sprintf(key, args...)
+}
+
+// TMDBLocale returns the locale to request TMDB content in for lang.
+func TMDBLocale(lang string) string {
+	for _, supported := range Supported {
+		if supported.Code == lang {
+			return supported.TMDB
+		}
+	}
+	return Supported[0].TMDB
+}
+
+// FromTMDBLocale returns the language whose TMDB locale is locale, or Default.
+func FromTMDBLocale(locale string) string {
+	for _, supported := range Supported {
+		if supported.TMDB == locale {
+			return supported.Code
+		}
+	}
+	return Default
+}
+
+// FormatDate formats t as a date in lang.
+func FormatDate(lang string, t time.Time) string {
+	for _, supported := range Supported {
+		if supported.Code == lang {
+			return t.Format(supported.DateLayout)
+		}
+	}
+	return t.Format(Supported[0].DateLayout)
+}
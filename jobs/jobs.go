@@ -0,0 +1,81 @@
+package jobs
+
+import (
+	"log"
+	"movie-tracker/config"
+	"movie-tracker/services"
+	"time"
+)
+
+// Start launches all periodic background jobs.
+func Start(cfg *config.Config) {
+	genreService := services.NewGenreService()
+	Every("genre sync", cfg.GenreSyncInterval, genreService.SyncGenres)
+
+	metadataService := services.NewMetadataService()
+	Every("metadata refresh", cfg.MetadataRefreshInterval, func() error {
+		return metadataService.RefreshStale(cfg.MetadataRefreshBatch, cfg.MetadataMaxAge, cfg.MetadataRequestDelay)
+	})
+
+	favoritesService := services.NewFavoritesService()
+	Every("trash purge", cfg.TrashPurgeInterval, favoritesService.PurgeExpiredTrash)
+
+	similarityService := services.NewSimilarityService()
+	Every("taste similarity", cfg.SimilarityInterval, func() error {
+		return similarityService.ComputeSimilarities(cfg.SimilarityNeighbors, cfg.SimilarityMinOverlap)
+	})
+
+	showService := services.NewShowService()
+	Every("show refresh", cfg.ShowRefreshInterval, func() error {
+		return showService.RefreshStale(cfg.ShowRefreshBatch, cfg.ShowMaxAge, cfg.MetadataRequestDelay)
+	})
+
+	providerService := services.NewProviderService()
+	Every("provider refresh", cfg.ProviderRefreshInterval, func() error {
+		return providerService.RefreshStale(cfg.ProviderRefreshBatch, cfg.ProviderMaxAge, cfg.MetadataRequestDelay)
+	})
+
+	calendarService := services.NewCalendarService()
+	Every("release date refresh", cfg.ReleaseRefreshInterval, func() error {
+		return calendarService.RefreshStale(cfg.ReleaseRefreshBatch, cfg.ReleaseMaxAge, cfg.MetadataRequestDelay)
+	})
+
+	notificationService := services.NewNotificationService()
+	Every("release notifications", cfg.ReleaseNotifyInterval, notificationService.NotifyReleases)
+	Every("weekly digest", cfg.DigestInterval, notificationService.SendDigests)
+
+	webhookService := services.NewWebhookService()
+	Every("webhook retries", cfg.WebhookRetryInterval, webhookService.DeliverDue)
+}
+
+// Every runs fn once right away and then on every interval in its own
+// goroutine. Errors are logged and the job keeps running. A non-positive
+// interval disables the job.
+func Every(name string, interval time.Duration, fn func() error) {
+	if interval <= 0 {
+		log.Printf("Background job %q disabled", name)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			run(name, fn)
+			<-ticker.C
+		}
+	}()
+}
+
+func run(name string, fn func() error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Background job %q panicked: %v", name, r)
+		}
+	}()
+
+	if err := fn(); err != nil {
+		log.Printf("Background job %q failed: %v", name, err)
+	}
+}
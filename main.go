@@ -5,8 +5,12 @@ import (
 	"log"
 	"movie-tracker/config"
 	"movie-tracker/database"
+	"movie-tracker/i18n"
+	"movie-tracker/jobs"
 	"movie-tracker/models"
 	"movie-tracker/routes"
+	"movie-tracker/services"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,6 +18,9 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	services.SetGenreLanguages(cfg.GenreLanguages)
+	services.SetTrashRetention(cfg.TrashRetention)
+	services.SetAllowPrivateWebhooks(cfg.WebhookAllowPrivate)
 
 	// Set Gin mode based on environment
 	if cfg.Environment == "production" {
@@ -25,15 +32,80 @@ func main() {
 
 	// Auto migrate database tables
 	db := database.GetDB()
-	if err := db.AutoMigrate(&models.User{}, &models.FavoriteMovie{}); err != nil {
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.FavoriteMovie{},
+		&models.Goal{},
+		&models.GenreTranslation{},
+		&models.Tag{},
+		&models.MovieList{},
+		&models.FavoriteHistory{},
+		&models.UserSimilarity{},
+		&models.MovieDirector{},
+		&models.TrackedShow{},
+		&models.TrackedEpisode{},
+		&models.MovieProvider{},
+		&models.MovieProviderSync{},
+		&models.MovieReleaseDate{},
+		&models.MovieReleaseSync{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.Follow{},
+		&models.Activity{},
+		&models.MovieRecommendation{},
+	); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	if err := database.SetupFullTextSearch(db); err != nil {
+		log.Fatal("Failed to set up full-text search:", err)
+	}
+
+	if err := database.DropLegacyStatusCheck(db); err != nil {
+		log.Fatal("Failed to migrate favorite statuses:", err)
+	}
+
+	if err := database.AllowUnsyncedProviders(db); err != nil {
+		log.Fatal("Failed to migrate provider syncs:", err)
+	}
+
+	if err := database.AllowUnsyncedReleases(db); err != nil {
+		log.Fatal("Failed to migrate release syncs:", err)
+	}
+
+	// Subscribe to domain events
+	services.NewWebhookService().Subscribe()
+	services.NewFollowService().Subscribe()
+
+	// Start background jobs
+	jobs.Start(cfg)
+
 	// Create Gin router
 	r := gin.Default()
 
 	// Add custom template functions
+	genreService := services.NewGenreService()
 	r.SetFuncMap(template.FuncMap{
+		"statuses": func() []models.StatusDefinition {
+			return models.StatusDefinitions
+		},
+		"statusInfo": func(status models.Status) models.StatusDefinition {
+			def, _ := models.GetStatusDefinition(status)
+			return def
+		},
+		"priorities": func() []models.PriorityDefinition {
+			return models.PriorityDefinitions
+		},
+		"priorityInfo": func(priority models.Priority) models.PriorityDefinition {
+			def, _ := models.GetPriorityDefinition(priority)
+			return def
+		},
+		"notificationInfo": func(t models.NotificationType) models.NotificationTypeDefinition {
+			def, _ := models.GetNotificationTypeDefinition(t)
+			return def
+		},
 		"seq": func(start, end int) []int {
 			var result []int
 			for i := start; i <= end; i++ {
@@ -41,6 +113,12 @@ func main() {
 			}
 			return result
 		},
+		"genreNames": func(lang string, ids []int) []string {
+			return genreService.WithLanguage(i18n.TMDBLocale(lang)).GetGenreNames(ids)
+		},
+		"t":         i18n.T,
+		"date":      i18n.FormatDate,
+		"hasPrefix": strings.HasPrefix,
 	})
 
 	// Load HTML templates
@@ -6,34 +6,49 @@ import (
 	"gorm.io/gorm"
 )
 
-type Status string
-
-const (
-	StatusToBe         Status = "por_ver"
-	StatusWatched      Status = "vista"
-	StatusRecommended  Status = "recomendada"
-)
-
 type FavoriteMovie struct {
-	ID            uint           `gorm:"primaryKey" json:"id"`
-	UserID        uint           `gorm:"not null;index" json:"user_id"`
-	TMDBId        int            `gorm:"not null" json:"tmdb_id"`
-	Title         string         `gorm:"not null;size:255" json:"title"`
-	Overview      string         `gorm:"type:text" json:"overview"`
-	ReleaseDate   *time.Time     `json:"release_date"`
-	PosterPath    string         `gorm:"size:255" json:"poster_path"`
-	GenreIDs      IntArray       `gorm:"type:jsonb" json:"genre_ids"`
-	Status        Status         `gorm:"type:varchar(20);default:'por_ver'" json:"status"`
-	Rating        *int           `gorm:"check:rating >= 1 AND rating <= 10" json:"rating"`
-	Notes         string         `gorm:"type:text" json:"notes"`
-	RecommendedBy string         `gorm:"size:100" json:"recommended_by"`
-	AddedAt       time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"added_at"`
-	WatchedAt     *time.Time     `json:"watched_at"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	UserID           uint           `gorm:"not null;index" json:"user_id"`
+	TMDBId           int            `gorm:"not null" json:"tmdb_id"`
+	Title            string         `gorm:"not null;size:255" json:"title"`
+	Overview         string         `gorm:"type:text" json:"overview"`
+	ReleaseDate      *time.Time     `json:"release_date"`
+	PosterPath       string         `gorm:"size:255" json:"poster_path"`
+	GenreIDs         IntArray       `gorm:"type:jsonb" json:"genre_ids"`
+	OriginalLanguage string         `gorm:"size:10" json:"original_language"`
+	Runtime          *int           `json:"runtime"`
+	OriginalTitle    string         `gorm:"size:255" json:"original_title"`
+	IMDBId           string         `gorm:"size:20" json:"imdb_id"`
+	BackdropPath     string         `gorm:"size:255" json:"backdrop_path"`
+	VoteAverage      float64        `json:"vote_average"`
+	MetadataSyncedAt *time.Time     `gorm:"index" json:"metadata_synced_at"`
+	MetadataTriedAt  *time.Time     `json:"-"` // Last refresh try, failed ones included
+	Status           Status         `gorm:"type:varchar(20);default:'por_ver'" json:"status"`
+	Position         float64        `gorm:"not null;default:0" json:"position"` // Watchlist order, lowest first
+	Priority         Priority       `gorm:"not null;default:0" json:"priority"`
+	SnoozedUntil     *time.Time     `json:"snoozed_until"` // Hidden from the picker until then
+	Rating           *int           `gorm:"check:rating >= 1 AND rating <= 10" json:"rating"`
+	Notes            string         `gorm:"type:text" json:"notes"`
+	RecommendedBy    string         `gorm:"size:100" json:"recommended_by"`
+	RecommenderID    *uint          `gorm:"index" json:"recommender_id"` // The user RecommendedBy names, for movies from accepted in-app recommendations
+	AddedAt          time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"added_at"`
+	WatchedAt        *time.Time     `json:"watched_at"`
+	ProgressMinutes  *int           `json:"progress_minutes"`
+	AbandonReason    string         `gorm:"type:text" json:"abandon_reason"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
 	
-	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	GenreNames []string `gorm:"-" json:"genre_names"`
+	// StreamingOn lists the user's services the movie can be streamed on.
+	// Only filled in for the watchlist.
+	StreamingOn []MovieProvider `gorm:"-" json:"streaming_on,omitempty"`
+
+	Tags  []Tag       `gorm:"many2many:favorite_movie_tags" json:"tags,omitempty"`
+	Lists []MovieList `gorm:"many2many:favorite_movie_lists" json:"lists,omitempty"`
+
+	User        User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Recommender *User `gorm:"foreignKey:RecommenderID;constraint:OnDelete:SET NULL" json:"-"`
 }
 
 func (FavoriteMovie) TableName() string {
@@ -47,10 +62,15 @@ func (fm *FavoriteMovie) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-func (fm *FavoriteMovie) BeforeUpdate(tx *gorm.DB) error {
-	if fm.Status == StatusWatched && fm.WatchedAt == nil {
-		now := time.Now()
-		fm.WatchedAt = &now
+// ProgressPercent returns how much of the movie has been watched, or 0 when
+// the progress or the runtime is unknown.
+func (fm FavoriteMovie) ProgressPercent() int {
+	if fm.ProgressMinutes == nil || fm.Runtime == nil || *fm.Runtime <= 0 {
+		return 0
 	}
-	return nil
+	percent := *fm.ProgressMinutes * 100 / *fm.Runtime
+	if percent > 100 {
+		return 100
+	}
+	return percent
 }
\ No newline at end of file
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// FollowStatus is whether a follow request was accepted.
+type FollowStatus string
+
+const (
+	FollowPending  FollowStatus = "pending"  // Waiting for a private profile to accept it
+	FollowAccepted FollowStatus = "accepted" // The follower sees the followee's activity
+)
+
+// Follow is a user following another. Following a public profile is
+// accepted right away; a private profile accepts or declines each request.
+type Follow struct {
+	FollowerID uint         `gorm:"primaryKey;autoIncrement:false" json:"follower_id"`
+	FolloweeID uint         `gorm:"primaryKey;autoIncrement:false;index" json:"followee_id"`
+	Status     FollowStatus `gorm:"type:varchar(10);not null" json:"status"`
+	CreatedAt  time.Time    `json:"created_at"`
+	AcceptedAt *time.Time   `json:"accepted_at"`
+
+	Follower User `gorm:"foreignKey:FollowerID" json:"-"`
+	Followee User `gorm:"foreignKey:FolloweeID" json:"-"`
+}
+
+func (Follow) TableName() string {
+	return "follows"
+}
+
+// ActivityType is what a user did that shows up in their followers' feeds.
+type ActivityType string
+
+const (
+	ActivityAdded   ActivityType = "added"   // Added a movie to their favorites
+	ActivityWatched ActivityType = "watched" // Marked a movie watched
+	ActivityRated   ActivityType = "rated"   // Rated a movie
+)
+
+// Activity is an entry of a user's activity feed, recorded from changes to
+// their favorites. Whether followers see it depends on the user's privacy
+// settings when the feed is read, and on the favorite not being deleted.
+type Activity struct {
+	ID              uint         `gorm:"primaryKey" json:"id"`
+	UserID          uint         `gorm:"not null;index:idx_activities_user_created" json:"user_id"`
+	FavoriteMovieID uint         `gorm:"not null;index" json:"favorite_movie_id"`
+	Type            ActivityType `gorm:"type:varchar(10);not null" json:"type"`
+	Rating          *int         `json:"rating,omitempty"` // The rating given, for rated activities
+	CreatedAt       time.Time    `gorm:"index:idx_activities_user_created" json:"created_at"`
+}
+
+func (Activity) TableName() string {
+	return "activities"
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// GenreTranslation is a TMDB movie genre name in a single language. Rows are
+// synced periodically from TMDB's genre list endpoint.
+type GenreTranslation struct {
+	GenreID   int       `gorm:"primaryKey;autoIncrement:false" json:"id"`
+	Language  string    `gorm:"primaryKey;size:10" json:"language"`
+	Name      string    `gorm:"not null;size:100" json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (GenreTranslation) TableName() string {
+	return "genres"
+}
+
+// GenreCount is the number of a user's movies tagged with a genre.
+type GenreCount struct {
+	GenreID int    `json:"genre_id"`
+	Name    string `json:"name"`
+	Count   int    `json:"count"`
+}
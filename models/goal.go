@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Goal is a user-defined watch target, e.g. "52 films in 2027" or
+// "10 films from before 1970". Progress is computed from the user's watched
+// FavoriteMovie rows that match the optional filters.
+type Goal struct {
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	UserID           uint           `gorm:"not null;index" json:"user_id"`
+	Title            string         `gorm:"not null;size:255" json:"title"`
+	Target           int            `gorm:"not null;check:target > 0" json:"target"`
+	StartsAt         *time.Time     `json:"starts_at"`
+	EndsAt           *time.Time     `json:"ends_at"`
+	GenreID          *int           `json:"genre_id"`
+	ReleaseYearFrom  *int           `json:"release_year_from"`
+	ReleaseYearTo    *int           `json:"release_year_to"`
+	OriginalLanguage string         `gorm:"size:10" json:"original_language"`
+	MinRuntime       *int           `json:"min_runtime"`
+	MaxRuntime       *int           `json:"max_runtime"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Goal) TableName() string {
+	return "goals"
+}
+
+// GoalProgress is the evaluated state of a Goal at a point in time.
+type GoalProgress struct {
+	Goal      Goal   `json:"goal"`
+	GenreName string `json:"genre_name,omitempty"`
+	Completed int    `json:"completed"`
+	Percent   int    `json:"percent"`
+	Expected  *int   `json:"expected,omitempty"`
+	Ahead     int    `json:"ahead"`
+	Pace      string `json:"pace"`
+	Done      bool   `json:"done"`
+}
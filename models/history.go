@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ChangeSource records where a change to a favorite came from.
+type ChangeSource string
+
+const (
+	SourceWeb ChangeSource = "web"
+	SourceAPI ChangeSource = "api"
+)
+
+// FavoriteHistory is an append-only record of a single field change on a
+// FavoriteMovie. Values are stored as text so any field can be tracked.
+type FavoriteHistory struct {
+	ID              uint         `gorm:"primaryKey" json:"id"`
+	FavoriteMovieID uint         `gorm:"not null;index" json:"favorite_movie_id"`
+	UserID          uint         `gorm:"not null;index" json:"user_id"`
+	Field           string       `gorm:"not null;size:50" json:"field"`
+	OldValue        string       `gorm:"type:text" json:"old_value"`
+	NewValue        string       `gorm:"type:text" json:"new_value"`
+	Source          ChangeSource `gorm:"type:varchar(10);not null" json:"source"`
+	CreatedAt       time.Time    `json:"created_at"`
+}
+
+func (FavoriteHistory) TableName() string {
+	return "favorite_history"
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ListVisibility is who can see a list outside the app.
+type ListVisibility string
+
+const (
+	ListPrivate  ListVisibility = "private"  // Only its owner
+	ListUnlisted ListVisibility = "unlisted" // Anyone with its share link
+	ListPublic   ListVisibility = "public"   // Shown on its owner's public page too
+)
+
+// ListVisibilities lists every visibility, from most to least private.
+var ListVisibilities = []ListVisibility{ListPrivate, ListUnlisted, ListPublic}
+
+// MovieList is a named, user-curated collection of favorite movies.
+type MovieList struct {
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	UserID      uint           `gorm:"not null;uniqueIndex:idx_movie_lists_user_slug" json:"user_id"`
+	Name        string         `gorm:"not null;size:100" json:"name"`
+	Slug        string         `gorm:"not null;size:100;uniqueIndex:idx_movie_lists_user_slug" json:"slug"`
+	Description string         `gorm:"type:text" json:"description"`
+	Visibility  ListVisibility `gorm:"type:varchar(10);not null;default:'private'" json:"visibility"`
+	ShareToken  *string        `gorm:"size:64;uniqueIndex" json:"-"` // In the share link of unlisted and public lists
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+func (MovieList) TableName() string {
+	return "movie_lists"
+}
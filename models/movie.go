@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"strings"
 )
 
 type IntArray []int
@@ -32,17 +33,20 @@ func (ia IntArray) Value() (driver.Value, error) {
 }
 
 type TMDBMovie struct {
-	ID           int      `json:"id"`
-	Title        string   `json:"title"`
-	Overview     string   `json:"overview"`
-	ReleaseDate  string   `json:"release_date"`
-	PosterPath   string   `json:"poster_path"`
-	GenreIDs     []int    `json:"genre_ids"`
-	VoteAverage  float64  `json:"vote_average"`
-	VoteCount    int      `json:"vote_count"`
-	Adult        bool     `json:"adult"`
-	OriginalTitle string  `json:"original_title"`
-	Popularity   float64  `json:"popularity"`
+	ID               int     `json:"id"`
+	Title            string  `json:"title"`
+	Overview         string  `json:"overview"`
+	ReleaseDate      string  `json:"release_date"`
+	PosterPath       string  `json:"poster_path"`
+	BackdropPath     string  `json:"backdrop_path"`
+	GenreIDs         []int   `json:"genre_ids"`
+	VoteAverage      float64 `json:"vote_average"`
+	VoteCount        int     `json:"vote_count"`
+	Adult            bool    `json:"adult"`
+	OriginalTitle    string  `json:"original_title"`
+	OriginalLanguage string  `json:"original_language"`
+	Runtime          int     `json:"runtime"`
+	Popularity       float64 `json:"popularity"`
 }
 
 type Genre struct {
@@ -94,6 +98,118 @@ type TMDBMovieDetail struct {
 	Video               bool                `json:"video"`
 	VoteAverage         float64             `json:"vote_average"`
 	VoteCount           int                 `json:"vote_count"`
+	Credits             TMDBCredits         `json:"credits"`
+	// Only filled in when requested with append_to_response=translations
+	Translations TMDBTranslations `json:"translations"`
+}
+
+type TMDBTranslations struct {
+	Translations []TMDBTranslation `json:"translations"`
+}
+
+// TMDBTranslation is a movie's title and overview in one locale. Empty
+// fields are not translated.
+type TMDBTranslation struct {
+	Language string `json:"iso_639_1"`
+	Region   string `json:"iso_3166_1"`
+	Data     struct {
+		Title    string `json:"title"`
+		Overview string `json:"overview"`
+	} `json:"data"`
+}
+
+// Localized returns the movie's title and overview in locale (e.g.
+// "es-MX"), preferring a translation for the same region over another one
+// in the same language. Missing translations fall back to the details' own
+// title and overview.
+func (d TMDBMovieDetail) Localized(locale string) (title, overview string) {
+	language, region, _ := strings.Cut(locale, "-")
+
+	var best *TMDBTranslation
+	for i, translation := range d.Translations.Translations {
+		if translation.Language != language {
+			continue
+		}
+		if best == nil || translation.Region == region {
+			best = &d.Translations.Translations[i]
+		}
+	}
+
+	title, overview = d.Title, d.Overview
+	if best != nil {
+		if best.Data.Title != "" {
+			title = best.Data.Title
+		}
+		if best.Data.Overview != "" {
+			overview = best.Data.Overview
+		}
+	}
+	return title, overview
+}
+
+// mainCastSize is how many cast members MainCast returns.
+const mainCastSize = 12
+
+// MainCast returns the top billed cast members.
+func (d TMDBMovieDetail) MainCast() []CastMember {
+	if len(d.Credits.Cast) > mainCastSize {
+		return d.Credits.Cast[:mainCastSize]
+	}
+	return d.Credits.Cast
+}
+
+// Directors returns the crew members credited as director.
+func (d TMDBMovieDetail) Directors() []CrewMember {
+	return d.Credits.CrewWithJob("Director")
+}
+
+// Writers returns the crew members credited for the screenplay or story.
+func (d TMDBMovieDetail) Writers() []CrewMember {
+	return d.Credits.CrewWithJob("Screenplay", "Writer", "Story", "Novel")
+}
+
+// CastMember is an actor in a movie's credits. Order is the billing order.
+type CastMember struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Character   string `json:"character"`
+	ProfilePath string `json:"profile_path"`
+	Order       int    `json:"order"`
+}
+
+// CrewMember is someone credited behind the camera.
+type CrewMember struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Job         string `json:"job"`
+	Department  string `json:"department"`
+	ProfilePath string `json:"profile_path"`
+}
+
+// TMDBCredits is a movie's cast and crew as returned by TMDB's credits
+// endpoint, or appended to the movie details with append_to_response=credits.
+type TMDBCredits struct {
+	Cast []CastMember `json:"cast"`
+	Crew []CrewMember `json:"crew"`
+}
+
+// CrewWithJob returns the crew members with any of jobs, each person once.
+func (c TMDBCredits) CrewWithJob(jobs ...string) []CrewMember {
+	var members []CrewMember
+	seen := make(map[int]bool)
+	for _, member := range c.Crew {
+		if seen[member.ID] {
+			continue
+		}
+		for _, job := range jobs {
+			if member.Job == job {
+				members = append(members, member)
+				seen[member.ID] = true
+				break
+			}
+		}
+	}
+	return members
 }
 
 type TMDBResponse struct {
@@ -101,4 +217,8 @@ type TMDBResponse struct {
 	Results      []TMDBMovie `json:"results"`
 	TotalPages   int         `json:"total_pages"`
 	TotalResults int         `json:"total_results"`
+}
+
+type TMDBGenreListResponse struct {
+	Genres []Genre `json:"genres"`
 }
\ No newline at end of file
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// MovieRecommendationStatus is how the recipient answered a recommendation.
+type MovieRecommendationStatus string
+
+const (
+	RecommendationPending  MovieRecommendationStatus = "pending"
+	RecommendationAccepted MovieRecommendationStatus = "accepted" // Added to the recipient's favorites
+	RecommendationDeclined MovieRecommendationStatus = "declined"
+)
+
+// MovieRecommendation is a movie a user recommended to another, with a
+// message. Accepting it adds the movie to the recipient's favorites with
+// RecommendedBy linked to the sender, so the sender can follow whether it
+// was watched and how it was rated.
+type MovieRecommendation struct {
+	ID          uint                      `gorm:"primaryKey" json:"id"`
+	SenderID    uint                      `gorm:"not null;index" json:"sender_id"`
+	RecipientID uint                      `gorm:"not null;index:idx_movie_recommendations_recipient" json:"recipient_id"`
+	TMDBId      int                       `gorm:"not null" json:"tmdb_id"`
+	Title       string                    `gorm:"not null;size:255" json:"title"` // In the sender's language
+	PosterPath  string                    `gorm:"size:255" json:"poster_path"`
+	Message     string                    `gorm:"type:text" json:"message"`
+	Status      MovieRecommendationStatus `gorm:"type:varchar(10);not null;default:'pending';index:idx_movie_recommendations_recipient" json:"status"`
+	// FavoriteMovieID is the recipient's favorite, once accepted
+	FavoriteMovieID *uint      `gorm:"index" json:"favorite_movie_id"`
+	RespondedAt     *time.Time `json:"responded_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+
+	Sender        User           `gorm:"foreignKey:SenderID" json:"-"`
+	Recipient     User           `gorm:"foreignKey:RecipientID" json:"-"`
+	FavoriteMovie *FavoriteMovie `gorm:"foreignKey:FavoriteMovieID;constraint:OnDelete:SET NULL" json:"-"`
+}
+
+func (MovieRecommendation) TableName() string {
+	return "movie_recommendations"
+}
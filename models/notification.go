@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// NotificationType is what a notification is about.
+type NotificationType string
+
+const (
+	NotificationRelease        NotificationType = "release"        // A watchlist movie comes out today
+	NotificationStreaming      NotificationType = "streaming"      // A watchlist movie can now be streamed on a subscribed service
+	NotificationRecommendation NotificationType = "recommendation" // Another user recommended a movie
+	NotificationFollow         NotificationType = "follow"         // A new follower, follow request or accepted request
+)
+
+// NotificationChannel is a way of delivering notifications.
+type NotificationChannel string
+
+const (
+	ChannelInApp   NotificationChannel = "in_app"
+	ChannelEmail   NotificationChannel = "email"
+	ChannelWebhook NotificationChannel = "webhook"
+)
+
+// NotificationTypeDefinition describes a notification type and the channels
+// it is delivered on until the user changes their preferences.
+type NotificationTypeDefinition struct {
+	Type            NotificationType
+	Label           string
+	Icon            string
+	DefaultChannels []NotificationChannel
+}
+
+// NotificationTypes lists every notification type in display order.
+var NotificationTypes = []NotificationTypeDefinition{
+	{Type: NotificationRelease, Label: "Watchlist movie released", Icon: "🎟️", DefaultChannels: []NotificationChannel{ChannelInApp}},
+	{Type: NotificationStreaming, Label: "Now streaming on your services", Icon: "▶️", DefaultChannels: []NotificationChannel{ChannelInApp}},
+	{Type: NotificationRecommendation, Label: "Recommended to you", Icon: "💌", DefaultChannels: []NotificationChannel{ChannelInApp, ChannelEmail}},
+	{Type: NotificationFollow, Label: "Followers and follow requests", Icon: "👋", DefaultChannels: []NotificationChannel{ChannelInApp}},
+}
+
+// NotificationChannelDefinition describes a delivery channel.
+type NotificationChannelDefinition struct {
+	Channel NotificationChannel
+	Label   string
+}
+
+// NotificationChannels lists every channel in display order.
+var NotificationChannels = []NotificationChannelDefinition{
+	{Channel: ChannelInApp, Label: "In-app"},
+	{Channel: ChannelEmail, Label: "Email"},
+	{Channel: ChannelWebhook, Label: "Webhook"},
+}
+
+// GetNotificationTypeDefinition returns the definition of t.
+func GetNotificationTypeDefinition(t NotificationType) (NotificationTypeDefinition, bool) {
+	for _, def := range NotificationTypes {
+		if def.Type == t {
+			return def, true
+		}
+	}
+	return NotificationTypeDefinition{}, false
+}
+
+// Notification is something a user was told about. Every notification is
+// stored, for the weekly digest, but only those delivered in-app show up in
+// the inbox.
+type Notification struct {
+	ID     uint             `gorm:"primaryKey" json:"id"`
+	UserID uint             `gorm:"not null;uniqueIndex:idx_notifications_user_key" json:"user_id"`
+	Type   NotificationType `gorm:"type:varchar(20);not null" json:"type"`
+	// Key identifies what the notification is about, so the same release or
+	// provider is never notified twice
+	Key        string     `gorm:"not null;size:100;uniqueIndex:idx_notifications_user_key" json:"-"`
+	Title      string     `gorm:"not null;size:255" json:"title"`
+	Body       string     `gorm:"type:text" json:"body"`
+	URL        string     `gorm:"size:255" json:"url"` // Relative link to what the notification is about
+	InApp      bool       `gorm:"not null;default:false" json:"in_app"`
+	ReadAt     *time.Time `json:"read_at"`
+	DigestedAt *time.Time `json:"digested_at"` // Set once included in a weekly digest
+	CreatedAt  time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationPreference overrides whether a type of notification is
+// delivered on a channel. Without a row the type's DefaultChannels apply.
+type NotificationPreference struct {
+	UserID  uint                `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	Type    NotificationType    `gorm:"primaryKey;type:varchar(20)" json:"type"`
+	Channel NotificationChannel `gorm:"primaryKey;type:varchar(10)" json:"channel"`
+	Enabled bool                `gorm:"not null" json:"enabled"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
@@ -0,0 +1,112 @@
+package models
+
+import "sort"
+
+// TMDBPerson is an actor or crew member with their movie credits, fetched
+// with append_to_response=movie_credits.
+type TMDBPerson struct {
+	ID                 int               `json:"id"`
+	Name               string            `json:"name"`
+	Biography          string            `json:"biography"`
+	Birthday           string            `json:"birthday"`
+	Deathday           string            `json:"deathday"`
+	PlaceOfBirth       string            `json:"place_of_birth"`
+	ProfilePath        string            `json:"profile_path"`
+	KnownForDepartment string            `json:"known_for_department"`
+	IMDBId             string            `json:"imdb_id"`
+	MovieCredits       TMDBPersonCredits `json:"movie_credits"`
+}
+
+// TMDBPersonCredits is the list of movies a person acted in or worked on.
+type TMDBPersonCredits struct {
+	Cast []PersonCastCredit `json:"cast"`
+	Crew []PersonCrewCredit `json:"crew"`
+}
+
+// PersonCastCredit is a movie a person acted in.
+type PersonCastCredit struct {
+	TMDBMovie
+	Character string `json:"character"`
+}
+
+// PersonCrewCredit is a movie a person worked on behind the camera.
+type PersonCrewCredit struct {
+	TMDBMovie
+	Job        string `json:"job"`
+	Department string `json:"department"`
+}
+
+// FilmographyEntry is one movie in a person's filmography with every role
+// they had in it, e.g. "Director" and "Writer".
+type FilmographyEntry struct {
+	Movie TMDBMovie `json:"movie"`
+	Roles []string  `json:"roles"`
+}
+
+// Filmography merges the person's cast and crew credits into one entry per
+// movie, newest first. Unreleased movies without a date come first.
+func (p TMDBPerson) Filmography() []FilmographyEntry {
+	byMovie := make(map[int]*FilmographyEntry)
+	var entries []*FilmographyEntry
+
+	add := func(movie TMDBMovie, role string) {
+		if movie.Adult {
+			return
+		}
+		entry, ok := byMovie[movie.ID]
+		if !ok {
+			entry = &FilmographyEntry{Movie: movie}
+			byMovie[movie.ID] = entry
+			entries = append(entries, entry)
+		}
+		for _, existing := range entry.Roles {
+			if existing == role {
+				return
+			}
+		}
+		entry.Roles = append(entry.Roles, role)
+	}
+
+	for _, credit := range p.MovieCredits.Cast {
+		role := "Actor"
+		if credit.Character != "" {
+			role = "as " + credit.Character
+		}
+		add(credit.TMDBMovie, role)
+	}
+	for _, credit := range p.MovieCredits.Crew {
+		add(credit.TMDBMovie, credit.Job)
+	}
+
+	// Release dates are YYYY-MM-DD, so they sort as strings
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].Movie.ReleaseDate, entries[j].Movie.ReleaseDate
+		if a == "" || b == "" {
+			return a == "" && b != ""
+		}
+		return a > b
+	})
+
+	filmography := make([]FilmographyEntry, 0, len(entries))
+	for _, entry := range entries {
+		filmography = append(filmography, *entry)
+	}
+	return filmography
+}
+
+// MovieDirector links a TMDB movie to one of its directors. Rows are kept up
+// to date by the metadata refresh job and power the directors stat.
+type MovieDirector struct {
+	TMDBId      int    `gorm:"primaryKey;autoIncrement:false" json:"tmdb_id"`
+	PersonID    int    `gorm:"primaryKey;autoIncrement:false;index" json:"person_id"`
+	Name        string `gorm:"not null;size:255" json:"name"`
+	ProfilePath string `gorm:"size:255" json:"profile_path"`
+}
+
+// DirectorCount is the number of a user's watched movies by a director.
+type DirectorCount struct {
+	PersonID    int    `json:"person_id"`
+	Name        string `json:"name"`
+	ProfilePath string `json:"profile_path"`
+	Count       int    `json:"count"`
+}
@@ -0,0 +1,41 @@
+package models
+
+// Priority flags a movie on the watchlist as more pressing than the rest.
+type Priority int
+
+const (
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+	PriorityUrgent Priority = 2
+)
+
+// PriorityDefinition describes how a priority is displayed.
+type PriorityDefinition struct {
+	Priority Priority `json:"priority"`
+	Label    string   `json:"label"`
+	Icon     string   `json:"icon"`
+	Color    string   `json:"color"` // Tailwind color name used for badges
+}
+
+// PriorityDefinitions lists the priorities from lowest to highest.
+var PriorityDefinitions = []PriorityDefinition{
+	{Priority: PriorityNormal, Label: "Normal", Icon: "", Color: "gray"},
+	{Priority: PriorityHigh, Label: "High", Icon: "🔥", Color: "indigo"},
+	{Priority: PriorityUrgent, Label: "Must watch", Icon: "🚨", Color: "red"},
+}
+
+// GetPriorityDefinition looks up the definition of priority.
+func GetPriorityDefinition(priority Priority) (PriorityDefinition, bool) {
+	for _, def := range PriorityDefinitions {
+		if def.Priority == priority {
+			return def, true
+		}
+	}
+	return PriorityDefinition{}, false
+}
+
+// IsValidPriority reports whether priority is defined.
+func IsValidPriority(priority Priority) bool {
+	_, ok := GetPriorityDefinition(priority)
+	return ok
+}
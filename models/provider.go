@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// Provider types as returned by TMDB. Only StreamingProviderTypes count as
+// "streamable now": the others cost extra on top of a subscription.
+const (
+	ProviderTypeFlatrate = "flatrate"
+	ProviderTypeFree     = "free"
+	ProviderTypeAds      = "ads"
+	ProviderTypeRent     = "rent"
+	ProviderTypeBuy      = "buy"
+)
+
+var StreamingProviderTypes = []string{ProviderTypeFlatrate, ProviderTypeFree, ProviderTypeAds}
+
+// TMDBWatchProvider is a streaming service, store or channel as returned by
+// TMDB (data provided by JustWatch).
+type TMDBWatchProvider struct {
+	ProviderID      int    `json:"provider_id"`
+	ProviderName    string `json:"provider_name"`
+	LogoPath        string `json:"logo_path"`
+	DisplayPriority int    `json:"display_priority"`
+}
+
+// TMDBRegionProviders lists where a movie can be watched in one region, by
+// provider type.
+type TMDBRegionProviders struct {
+	Link     string              `json:"link"`
+	Flatrate []TMDBWatchProvider `json:"flatrate"`
+	Free     []TMDBWatchProvider `json:"free"`
+	Ads      []TMDBWatchProvider `json:"ads"`
+	Rent     []TMDBWatchProvider `json:"rent"`
+	Buy      []TMDBWatchProvider `json:"buy"`
+}
+
+// ByType returns the region's providers keyed by provider type.
+func (r TMDBRegionProviders) ByType() map[string][]TMDBWatchProvider {
+	return map[string][]TMDBWatchProvider{
+		ProviderTypeFlatrate: r.Flatrate,
+		ProviderTypeFree:     r.Free,
+		ProviderTypeAds:      r.Ads,
+		ProviderTypeRent:     r.Rent,
+		ProviderTypeBuy:      r.Buy,
+	}
+}
+
+// TMDBWatchProvidersResponse is a movie's providers keyed by ISO 3166-1
+// region code, e.g. "MX".
+type TMDBWatchProvidersResponse struct {
+	ID      int                            `json:"id"`
+	Results map[string]TMDBRegionProviders `json:"results"`
+}
+
+type TMDBProviderListResponse struct {
+	Results []TMDBWatchProvider `json:"results"`
+}
+
+// TMDBRegion is a country TMDB has provider data for.
+type TMDBRegion struct {
+	Code        string `json:"iso_3166_1"`
+	EnglishName string `json:"english_name"`
+}
+
+type TMDBRegionListResponse struct {
+	Results []TMDBRegion `json:"results"`
+}
+
+// MovieProvider caches that a movie is offered by a provider in a region.
+// Rows are replaced whenever the movie's providers are refreshed.
+type MovieProvider struct {
+	TMDBId          int    `gorm:"primaryKey;autoIncrement:false" json:"tmdb_id"`
+	Region          string `gorm:"primaryKey;size:2" json:"region"`
+	ProviderID      int    `gorm:"primaryKey;autoIncrement:false;index" json:"provider_id"`
+	Type            string `gorm:"primaryKey;size:10" json:"type"`
+	ProviderName    string `gorm:"not null;size:100" json:"provider_name"`
+	LogoPath        string `gorm:"size:255" json:"logo_path"`
+	DisplayPriority int    `json:"display_priority"`
+}
+
+func (MovieProvider) TableName() string {
+	return "movie_providers"
+}
+
+// MovieProviderSync records when a movie's providers were last fetched for
+// a region, so movies available nowhere are not fetched again every run.
+// SyncedAt stays nil until a fetch succeeds.
+type MovieProviderSync struct {
+	TMDBId   int        `gorm:"primaryKey;autoIncrement:false" json:"tmdb_id"`
+	Region   string     `gorm:"primaryKey;size:2" json:"region"`
+	SyncedAt *time.Time `gorm:"index" json:"synced_at"`
+	TriedAt  *time.Time `json:"-"` // Last refresh try, failed ones included
+}
+
+func (MovieProviderSync) TableName() string {
+	return "movie_provider_syncs"
+}
@@ -0,0 +1,104 @@
+package models
+
+import "time"
+
+// ReleaseType is how a movie is released in a region, as numbered by TMDB.
+type ReleaseType int
+
+const (
+	// ReleaseTypePrimary is the movie's primary release date, used when no
+	// regional dates are known.
+	ReleaseTypePrimary ReleaseType = iota
+	ReleaseTypePremiere
+	ReleaseTypeTheatricalLimited
+	ReleaseTypeTheatrical
+	ReleaseTypeDigital
+	ReleaseTypePhysical
+	ReleaseTypeTV
+)
+
+// CalendarReleaseTypes are the regional release types shown on the
+// calendar: festival premieres and TV airings are left out.
+var CalendarReleaseTypes = []ReleaseType{
+	ReleaseTypeTheatricalLimited,
+	ReleaseTypeTheatrical,
+	ReleaseTypeDigital,
+	ReleaseTypePhysical,
+}
+
+var releaseTypeLabels = map[ReleaseType]string{
+	ReleaseTypePrimary:           "Release",
+	ReleaseTypePremiere:          "Premiere",
+	ReleaseTypeTheatricalLimited: "Limited theatrical",
+	ReleaseTypeTheatrical:        "Theatrical",
+	ReleaseTypeDigital:           "Digital",
+	ReleaseTypePhysical:          "Physical",
+	ReleaseTypeTV:                "TV",
+}
+
+// Label returns the English name of the release type.
+func (t ReleaseType) Label() string {
+	return releaseTypeLabels[t]
+}
+
+// Icon returns an emoji for the release type.
+func (t ReleaseType) Icon() string {
+	switch t {
+	case ReleaseTypeTheatricalLimited, ReleaseTypeTheatrical:
+		return "🎟️"
+	case ReleaseTypeDigital:
+		return "💻"
+	case ReleaseTypePhysical:
+		return "📀"
+	case ReleaseTypeTV:
+		return "📺"
+	default:
+		return "🎬"
+	}
+}
+
+// TMDBReleaseDate is one release of a movie in a region.
+type TMDBReleaseDate struct {
+	Certification string      `json:"certification"`
+	Note          string      `json:"note"`
+	ReleaseDate   time.Time   `json:"release_date"`
+	Type          ReleaseType `json:"type"`
+}
+
+type TMDBRegionReleaseDates struct {
+	Region       string            `json:"iso_3166_1"`
+	ReleaseDates []TMDBReleaseDate `json:"release_dates"`
+}
+
+type TMDBReleaseDatesResponse struct {
+	ID      int                      `json:"id"`
+	Results []TMDBRegionReleaseDates `json:"results"`
+}
+
+// MovieReleaseDate caches a movie's release of one type in a region. Rows
+// are replaced whenever the movie's release dates are refreshed.
+type MovieReleaseDate struct {
+	TMDBId int         `gorm:"primaryKey;autoIncrement:false" json:"tmdb_id"`
+	Region string      `gorm:"primaryKey;size:2" json:"region"`
+	Type   ReleaseType `gorm:"primaryKey;autoIncrement:false" json:"type"`
+	Date   time.Time   `gorm:"primaryKey;type:date" json:"date"`
+	Note   string      `gorm:"type:text" json:"note"` // e.g. the festival or streaming service
+}
+
+func (MovieReleaseDate) TableName() string {
+	return "movie_release_dates"
+}
+
+// MovieReleaseSync records when a movie's release dates were last fetched
+// for a region, so movies without regional dates are not fetched again
+// every run. SyncedAt stays nil until a fetch succeeds.
+type MovieReleaseSync struct {
+	TMDBId   int        `gorm:"primaryKey;autoIncrement:false" json:"tmdb_id"`
+	Region   string     `gorm:"primaryKey;size:2" json:"region"`
+	SyncedAt *time.Time `gorm:"index" json:"synced_at"`
+	TriedAt  *time.Time `json:"-"` // Last refresh try, failed ones included
+}
+
+func (MovieReleaseSync) TableName() string {
+	return "movie_release_syncs"
+}
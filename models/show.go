@@ -0,0 +1,183 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// TMDBShow is a TV series as returned by TMDB's search and list endpoints.
+type TMDBShow struct {
+	ID               int     `json:"id"`
+	Name             string  `json:"name"`
+	OriginalName     string  `json:"original_name"`
+	Overview         string  `json:"overview"`
+	FirstAirDate     string  `json:"first_air_date"`
+	PosterPath       string  `json:"poster_path"`
+	BackdropPath     string  `json:"backdrop_path"`
+	GenreIDs         []int   `json:"genre_ids"`
+	OriginalLanguage string  `json:"original_language"`
+	VoteAverage      float64 `json:"vote_average"`
+	VoteCount        int     `json:"vote_count"`
+	Popularity       float64 `json:"popularity"`
+}
+
+type TMDBShowResponse struct {
+	Page         int        `json:"page"`
+	Results      []TMDBShow `json:"results"`
+	TotalPages   int        `json:"total_pages"`
+	TotalResults int        `json:"total_results"`
+}
+
+// TMDBShowDetail is a TV series with its list of seasons. Episodes are
+// fetched per season with TMDBService.GetSeasonDetails.
+type TMDBShowDetail struct {
+	ID               int                 `json:"id"`
+	Name             string              `json:"name"`
+	OriginalName     string              `json:"original_name"`
+	Overview         string              `json:"overview"`
+	Tagline          string              `json:"tagline"`
+	FirstAirDate     string              `json:"first_air_date"`
+	LastAirDate      string              `json:"last_air_date"`
+	PosterPath       string              `json:"poster_path"`
+	BackdropPath     string              `json:"backdrop_path"`
+	Genres           []Genre             `json:"genres"`
+	OriginalLanguage string              `json:"original_language"`
+	Status           string              `json:"status"` // e.g. "Returning Series", "Ended", "Canceled"
+	NumberOfSeasons  int                 `json:"number_of_seasons"`
+	NumberOfEpisodes int                 `json:"number_of_episodes"`
+	EpisodeRunTime   []int               `json:"episode_run_time"`
+	VoteAverage      float64             `json:"vote_average"`
+	VoteCount        int                 `json:"vote_count"`
+	Seasons          []TMDBSeasonSummary `json:"seasons"`
+	NextEpisodeToAir *TMDBEpisode        `json:"next_episode_to_air"`
+}
+
+// TMDBSeasonSummary is a season as listed in a show's details.
+type TMDBSeasonSummary struct {
+	ID           int    `json:"id"`
+	SeasonNumber int    `json:"season_number"`
+	Name         string `json:"name"`
+	EpisodeCount int    `json:"episode_count"`
+	AirDate      string `json:"air_date"`
+	PosterPath   string `json:"poster_path"`
+}
+
+// TMDBSeason is a season with all its episodes.
+type TMDBSeason struct {
+	ID           int           `json:"id"`
+	SeasonNumber int           `json:"season_number"`
+	Name         string        `json:"name"`
+	Overview     string        `json:"overview"`
+	AirDate      string        `json:"air_date"`
+	PosterPath   string        `json:"poster_path"`
+	Episodes     []TMDBEpisode `json:"episodes"`
+}
+
+type TMDBEpisode struct {
+	ID            int     `json:"id"`
+	SeasonNumber  int     `json:"season_number"`
+	EpisodeNumber int     `json:"episode_number"`
+	Name          string  `json:"name"`
+	Overview      string  `json:"overview"`
+	AirDate       string  `json:"air_date"`
+	Runtime       int     `json:"runtime"`
+	StillPath     string  `json:"still_path"`
+	VoteAverage   float64 `json:"vote_average"`
+}
+
+// TrackedShow is a TV series on a user's list. Its Status follows the
+// episodes: por_ver until one is watched, viendo while some are left and
+// vista once every aired episode has been watched.
+type TrackedShow struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	UserID           uint       `gorm:"not null;uniqueIndex:idx_tracked_shows_user_tmdb" json:"user_id"`
+	TMDBId           int        `gorm:"not null;uniqueIndex:idx_tracked_shows_user_tmdb" json:"tmdb_id"`
+	Name             string     `gorm:"not null;size:255" json:"name"`
+	OriginalName     string     `gorm:"size:255" json:"original_name"`
+	Overview         string     `gorm:"type:text" json:"overview"`
+	FirstAirDate     *time.Time `json:"first_air_date"`
+	PosterPath       string     `gorm:"size:255" json:"poster_path"`
+	BackdropPath     string     `gorm:"size:255" json:"backdrop_path"`
+	GenreIDs         IntArray   `gorm:"type:jsonb" json:"genre_ids"`
+	OriginalLanguage string     `gorm:"size:10" json:"original_language"`
+	ShowStatus       string     `gorm:"size:50" json:"show_status"` // TMDB's status, e.g. "Returning Series"
+	EpisodeRunTime   *int       `json:"episode_run_time"`           // Typical episode length in minutes
+	VoteAverage      float64    `json:"vote_average"`
+	Status           Status     `gorm:"type:varchar(20);default:'por_ver'" json:"status"`
+	SyncedAt         *time.Time `gorm:"index" json:"synced_at"`
+	TriedAt          *time.Time `json:"-"` // Last refresh try, failed ones included
+	AddedAt          time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"added_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	Episodes []TrackedEpisode `gorm:"constraint:OnDelete:CASCADE" json:"episodes,omitempty"`
+
+	// Filled in by ShowService, not stored
+	WatchedEpisodes int `gorm:"-" json:"watched_episodes"`
+	AiredEpisodes   int `gorm:"-" json:"aired_episodes"`
+}
+
+func (TrackedShow) TableName() string {
+	return "tracked_shows"
+}
+
+// ProgressPercent returns the share of aired episodes watched.
+func (s TrackedShow) ProgressPercent() int {
+	if s.AiredEpisodes == 0 {
+		return 0
+	}
+	return s.WatchedEpisodes * 100 / s.AiredEpisodes
+}
+
+// TrackedEpisode is one episode of a TrackedShow. WatchedAt is nil until the
+// user watches it. Specials (season 0) are not tracked.
+type TrackedEpisode struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	TrackedShowID uint       `gorm:"not null;uniqueIndex:idx_tracked_episodes_show_number" json:"tracked_show_id"`
+	SeasonNumber  int        `gorm:"not null;uniqueIndex:idx_tracked_episodes_show_number" json:"season_number"`
+	EpisodeNumber int        `gorm:"not null;uniqueIndex:idx_tracked_episodes_show_number" json:"episode_number"`
+	TMDBId        int        `json:"tmdb_id"`
+	Name          string     `gorm:"size:255" json:"name"`
+	Overview      string     `gorm:"type:text" json:"overview"`
+	AirDate       *time.Time `json:"air_date"`
+	Runtime       *int       `json:"runtime"`
+	StillPath     string     `gorm:"size:255" json:"still_path"`
+	WatchedAt     *time.Time `json:"watched_at"`
+}
+
+func (TrackedEpisode) TableName() string {
+	return "tracked_episodes"
+}
+
+// Aired reports whether the episode has already been broadcast.
+func (e TrackedEpisode) Aired() bool {
+	return e.AirDate != nil && !e.AirDate.After(time.Now())
+}
+
+// Code returns the usual short episode code, e.g. "S02E05".
+func (e TrackedEpisode) Code() string {
+	return fmt.Sprintf("S%02dE%02d", e.SeasonNumber, e.EpisodeNumber)
+}
+
+// Season groups a tracked show's episodes for display.
+type Season struct {
+	ShowID   uint             `json:"show_id"`
+	Number   int              `json:"number"`
+	Episodes []TrackedEpisode `json:"episodes"`
+	Watched  int              `json:"watched"`
+	Aired    int              `json:"aired"`
+}
+
+// Complete reports whether every aired episode of the season was watched.
+func (s Season) Complete() bool {
+	return s.Aired > 0 && s.Watched >= s.Aired
+}
+
+// NextEpisode is the first unwatched aired episode of a show the user is
+// watching.
+type NextEpisode struct {
+	Show    TrackedShow    `json:"show"`
+	Episode TrackedEpisode `json:"episode"`
+	// Remaining counts the aired episodes left, including this one
+	Remaining int `json:"remaining"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// UserSimilarity is one of a user's nearest neighbours by taste, computed in
+// batch from the ratings both users gave to the same movies.
+type UserSimilarity struct {
+	UserID     uint      `gorm:"primaryKey" json:"user_id"`
+	NeighborID uint      `gorm:"primaryKey" json:"neighbor_id"`
+	Similarity float64   `gorm:"not null" json:"similarity"`
+	Overlap    int       `gorm:"not null" json:"overlap"` // Movies rated by both users
+	ComputedAt time.Time `gorm:"not null" json:"computed_at"`
+}
+
+func (UserSimilarity) TableName() string {
+	return "user_similarities"
+}
@@ -0,0 +1,130 @@
+package models
+
+type Status string
+
+const (
+	StatusToBe        Status = "por_ver"
+	StatusWatched     Status = "vista"
+	StatusRecommended Status = "recomendada"
+	StatusWatching    Status = "viendo"
+	StatusAbandoned   Status = "abandonada"
+)
+
+// StatusEffect is a side effect applied when a movie enters a status.
+type StatusEffect string
+
+const (
+	EffectSetWatchedAt   StatusEffect = "set_watched_at"
+	EffectClearWatchedAt StatusEffect = "clear_watched_at"
+	EffectPromptRating   StatusEffect = "prompt_rating"
+	EffectStartProgress  StatusEffect = "start_progress"
+	EffectClearProgress  StatusEffect = "clear_progress"
+	EffectClearReason    StatusEffect = "clear_abandon_reason"
+)
+
+// StatusDefinition describes a status: how it is displayed, which statuses a
+// movie may move to from it and what happens when a movie enters or leaves it.
+type StatusDefinition struct {
+	Status         Status         `json:"status"`
+	Label          string         `json:"label"`
+	Icon           string         `json:"icon"`
+	Color          string         `json:"color"` // Tailwind color name used for badges and cards
+	Slug           string         `json:"slug"`  // Shortcut path, e.g. /favorites/por-ver
+	Transitions    []Status       `json:"transitions"`
+	OnEnter        []StatusEffect `json:"on_enter"`
+	OnLeave        []StatusEffect `json:"on_leave"`
+	TracksProgress bool           `json:"tracks_progress"` // Whether minutes watched can be recorded
+	HasReason      bool           `json:"has_reason"`      // Whether the status carries a free-text reason
+}
+
+// StatusDefinitions is the single source of truth for movie statuses and is
+// listed in display order. Adding a status only requires a new entry here
+// (and adding it to the Transitions of the statuses that may lead to it).
+var StatusDefinitions = []StatusDefinition{
+	{
+		Status:      StatusToBe,
+		Label:       "To Watch",
+		Icon:        "📝",
+		Color:       "blue",
+		Slug:        "por-ver",
+		Transitions: []Status{StatusWatching, StatusWatched, StatusRecommended, StatusAbandoned},
+		OnEnter:     []StatusEffect{EffectClearWatchedAt, EffectClearProgress},
+	},
+	{
+		Status:         StatusWatching,
+		Label:          "Watching",
+		Icon:           "▶️",
+		Color:          "purple",
+		Slug:           "viendo",
+		Transitions:    []Status{StatusToBe, StatusWatched, StatusAbandoned},
+		OnEnter:        []StatusEffect{EffectStartProgress},
+		TracksProgress: true,
+	},
+	{
+		Status:      StatusWatched,
+		Label:       "Watched",
+		Icon:        "✅",
+		Color:       "green",
+		Slug:        "vistas",
+		Transitions: []Status{StatusToBe, StatusWatching, StatusRecommended},
+		OnEnter:     []StatusEffect{EffectSetWatchedAt, EffectClearProgress, EffectPromptRating},
+	},
+	{
+		Status:      StatusRecommended,
+		Label:       "Recommended",
+		Icon:        "⭐",
+		Color:       "yellow",
+		Slug:        "recomendadas",
+		Transitions: []Status{StatusToBe, StatusWatching, StatusWatched},
+	},
+	{
+		Status:         StatusAbandoned,
+		Label:          "Abandoned",
+		Icon:           "🚫",
+		Color:          "gray",
+		Slug:           "abandonadas",
+		Transitions:    []Status{StatusToBe, StatusWatching, StatusWatched},
+		OnLeave:        []StatusEffect{EffectClearReason},
+		TracksProgress: true,
+		HasReason:      true,
+	},
+}
+
+// GetStatusDefinition looks up the definition of status.
+func GetStatusDefinition(status Status) (StatusDefinition, bool) {
+	for _, def := range StatusDefinitions {
+		if def.Status == status {
+			return def, true
+		}
+	}
+	return StatusDefinition{}, false
+}
+
+// IsValidStatus reports whether status is defined.
+func IsValidStatus(status Status) bool {
+	_, ok := GetStatusDefinition(status)
+	return ok
+}
+
+// CanTransitionTo reports whether a movie in this status may move to status.
+func (d StatusDefinition) CanTransitionTo(status Status) bool {
+	if status == d.Status {
+		return true
+	}
+	for _, allowed := range d.Transitions {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// HasEffect reports whether entering this status triggers effect.
+func (d StatusDefinition) HasEffect(effect StatusEffect) bool {
+	for _, e := range d.OnEnter {
+		if e == effect {
+			return true
+		}
+	}
+	return false
+}
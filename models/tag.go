@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Tag is a free-form label a user attaches to their movies ("cozy", "date night").
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_tags_user_name" json:"user_id"`
+	Name      string    `gorm:"not null;size:50;uniqueIndex:idx_tags_user_name" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
@@ -8,14 +8,29 @@ import (
 )
 
 type User struct {
-	ID           uint           `gorm:"primaryKey" json:"id"`
-	Username     string         `gorm:"unique;not null;size:50" json:"username"`
-	Email        string         `gorm:"unique;not null;size:255" json:"email"`
-	PasswordHash string         `gorm:"not null;size:255" json:"-"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
-	
+	ID                  uint           `gorm:"primaryKey" json:"id"`
+	Username            string         `gorm:"unique;not null;size:50" json:"username"`
+	Email               string         `gorm:"unique;not null;size:255" json:"email"`
+	PasswordHash        string         `gorm:"not null;size:255" json:"-"`
+	TasteMatchingOptOut bool           `gorm:"not null;default:false" json:"taste_matching_opt_out"` // Left out of similar-taste matching
+	Language            string         `gorm:"size:5" json:"language"`                               // UI and TMDB content language, e.g. "es"; empty follows the browser
+	Region              string         `gorm:"size:2" json:"region"`                                 // ISO 3166-1 country for watch providers, e.g. "MX"
+	StreamingServices   IntArray       `gorm:"type:jsonb" json:"streaming_services"`                 // TMDB provider IDs the user subscribes to
+	CalendarToken       *string        `gorm:"size:64;uniqueIndex" json:"-"`                         // Authenticates the private release calendar feed
+	NotificationWebhook string         `gorm:"size:255" json:"notification_webhook"`                 // URL notifications are POSTed to on the webhook channel
+	WeeklyDigest        bool           `gorm:"not null;default:false" json:"weekly_digest"`          // Email a summary of the week's notifications
+	DigestSentAt        *time.Time     `json:"-"`
+	PrivateProfile      bool           `gorm:"not null;default:false" json:"private_profile"`   // Follow requests need the user's approval
+	FeedHideAdded       bool           `gorm:"not null;default:false" json:"feed_hide_added"`   // Keep movies the user adds out of followers' feeds
+	FeedHideWatched     bool           `gorm:"not null;default:false" json:"feed_hide_watched"` // Keep movies the user watches out of followers' feeds
+	FeedHideRatings     bool           `gorm:"not null;default:false" json:"feed_hide_ratings"` // Keep the user's ratings out of followers' feeds
+	PublicPage          bool           `gorm:"not null;default:false" json:"public_page"`       // Anyone can see /u/<username> and the user's public lists
+	PublicRatings       bool           `gorm:"not null;default:false" json:"public_ratings"`    // Show the user's ratings on their public page
+	AllowIndexing       bool           `gorm:"not null;default:false" json:"allow_indexing"`    // Let search engines index the user's public pages
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
+
 	FavoriteMovies []FavoriteMovie `gorm:"foreignKey:UserID" json:"favorite_movies,omitempty"`
 }
 
@@ -33,6 +48,16 @@ func (u *User) CheckPassword(password string) bool {
 	return err == nil
 }
 
+// UserProfile is what other users can see of a user.
+type UserProfile struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+func (u *User) Profile() UserProfile {
+	return UserProfile{ID: u.ID, Username: u.Username}
+}
+
 func (User) TableName() string {
 	return "users"
-}
\ No newline at end of file
+}
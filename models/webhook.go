@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Webhook is a URL a user registered to receive their favorites events.
+// Payloads are signed with Secret so the receiver can check they come from
+// this app.
+type Webhook struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	URL       string    `gorm:"not null;size:255" json:"url"`
+	Secret    string    `gorm:"not null;size:64" json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Deliveries []WebhookDelivery `gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE" json:"deliveries,omitempty"`
+}
+
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// WebhookDeliveryStatus is where a delivery is in its retries.
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"   // Waiting for its first attempt or a retry
+	DeliveryDelivered WebhookDeliveryStatus = "delivered" // The receiver answered with a 2xx status
+	DeliveryFailed    WebhookDeliveryStatus = "failed"    // Every attempt failed
+)
+
+// WebhookDelivery is one event sent, or being sent, to a webhook, with the
+// outcome of its last attempt.
+type WebhookDelivery struct {
+	ID            uint                  `gorm:"primaryKey" json:"id"`
+	WebhookID     uint                  `gorm:"not null;index" json:"webhook_id"`
+	Event         string                `gorm:"not null;size:50" json:"event"`
+	Payload       string                `gorm:"type:text;not null" json:"payload"`
+	Status        WebhookDeliveryStatus `gorm:"type:varchar(10);not null;default:'pending'" json:"status"`
+	Attempts      int                   `gorm:"not null;default:0" json:"attempts"`
+	StatusCode    *int                  `json:"status_code"` // Of the last attempt; nil when no response was received
+	Error         string                `gorm:"type:text" json:"error,omitempty"`
+	ResponseBody  string                `gorm:"type:text" json:"response_body,omitempty"` // Start of the last response
+	DurationMs    int                   `json:"duration_ms"`
+	NextAttemptAt *time.Time            `gorm:"index" json:"next_attempt_at"` // Nil once delivered or failed
+	CreatedAt     time.Time             `gorm:"index" json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"movie-tracker/handlers"
 	"movie-tracker/middleware"
+	"movie-tracker/models"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +16,17 @@ func SetupRoutes(r *gin.Engine) {
 	tmdbHandler := handlers.NewTMDBHandler()
 	favoritesHandler := handlers.NewFavoritesHandler()
 	userHandler := handlers.NewUserHandler()
+	goalsHandler := handlers.NewGoalsHandler()
+	pickerHandler := handlers.NewPickerHandler()
+	recommendationHandler := handlers.NewRecommendationHandler()
+	settingsHandler := handlers.NewSettingsHandler()
+	showHandler := handlers.NewShowHandler()
+	calendarHandler := handlers.NewCalendarHandler()
+	notificationHandler := handlers.NewNotificationHandler()
+	webhookHandler := handlers.NewWebhookHandler()
+	followHandler := handlers.NewFollowHandler()
+	movieRecommendationHandler := handlers.NewMovieRecommendationHandler()
+	publicPageHandler := handlers.NewPublicPageHandler()
 
 	// Root redirect
 	r.GET("/", func(c *gin.Context) {
@@ -38,6 +50,14 @@ func SetupRoutes(r *gin.Engine) {
 		public.POST("/register", authHandler.Register)
 	}
 
+	// Calendar feed, authenticated by the token in its URL (/calendar/<token>.ics)
+	r.GET("/calendar/:token", calendarHandler.Feed)
+
+	// Public pages users chose to share, open without login
+	r.GET("/robots.txt", publicPageHandler.RobotsTxt)
+	r.GET("/u/:username", publicPageHandler.ShowProfile)
+	r.GET("/u/:username/lists/:slug", publicPageHandler.ShowList)
+
 	// Authentication logout (available to authenticated users)
 	r.POST("/logout", middleware.AuthMiddleware(), authHandler.Logout)
 
@@ -51,16 +71,26 @@ func SetupRoutes(r *gin.Engine) {
 		// Movie pages
 		protected.GET("/search", favoritesHandler.ShowSearch)
 		protected.GET("/favorites", favoritesHandler.ShowFavorites)
+		protected.GET("/favorites/trash", favoritesHandler.ShowTrash)
 		protected.GET("/movie/:id", tmdbHandler.GetMovieDetail)
-		protected.GET("/favorites/por-ver", func(c *gin.Context) {
-			c.Redirect(http.StatusFound, "/favorites?status=por_ver")
-		})
-		protected.GET("/favorites/vistas", func(c *gin.Context) {
-			c.Redirect(http.StatusFound, "/favorites?status=vista")
-		})
-		protected.GET("/favorites/recomendadas", func(c *gin.Context) {
-			c.Redirect(http.StatusFound, "/favorites?status=recomendada")
-		})
+		protected.GET("/person/:id", tmdbHandler.GetPersonDetail)
+		protected.GET("/settings", settingsHandler.ShowSettings)
+		protected.GET("/calendar", calendarHandler.ShowCalendar)
+		protected.GET("/notifications", notificationHandler.ShowNotifications)
+		protected.GET("/notifications/:id", notificationHandler.OpenNotification)
+		protected.GET("/webhooks", webhookHandler.ShowWebhooks)
+		protected.GET("/people", followHandler.ShowPeople)
+		protected.GET("/recommended", movieRecommendationHandler.ShowRecommended)
+		for _, def := range models.StatusDefinitions {
+			target := "/favorites?status=" + string(def.Status)
+			protected.GET("/favorites/"+def.Slug, func(c *gin.Context) {
+				c.Redirect(http.StatusFound, target)
+			})
+		}
+
+		// TV pages
+		protected.GET("/tv", showHandler.ShowShows)
+		protected.GET("/tv/:id", showHandler.GetShowDetail)
 	}
 
 	// API routes
@@ -71,14 +101,93 @@ func SetupRoutes(r *gin.Engine) {
 		api.GET("/movies/search", tmdbHandler.SearchMovies)
 		api.GET("/movies/popular", tmdbHandler.GetPopularMovies)
 		api.GET("/movies/trending", tmdbHandler.GetTrendingMovies)
+		api.GET("/movies/discover", tmdbHandler.DiscoverMovies)
+		api.GET("/movies/:id/similar", tmdbHandler.GetSimilarMovies)
+		api.GET("/movies/:id/recommendations", tmdbHandler.GetMovieRecommendations)
 
 		// Favorites API
+		api.GET("/favorites", favoritesHandler.ListFavorites)
 		api.POST("/favorites", favoritesHandler.AddToFavorites)
+		api.POST("/favorites/bulk", favoritesHandler.BulkUpdate)
 		api.PATCH("/favorites/:id/status", favoritesHandler.UpdateStatus)
 		api.PATCH("/favorites/:id/rating", favoritesHandler.UpdateRating)
+		api.PATCH("/favorites/:id/progress", favoritesHandler.UpdateProgress)
+		api.PATCH("/favorites/:id/priority", favoritesHandler.UpdatePriority)
+		api.PATCH("/favorites/:id/position", favoritesHandler.MoveFavorite)
+		api.POST("/favorites/:id/top", favoritesHandler.MoveToTop)
+		api.POST("/favorites/top", favoritesHandler.MoveToTop)
 		api.DELETE("/favorites/:id", favoritesHandler.DeleteFavorite)
+		api.GET("/favorites/trash", favoritesHandler.ListTrash)
+		api.DELETE("/favorites/trash", favoritesHandler.EmptyTrash)
+		api.POST("/favorites/:id/restore", favoritesHandler.RestoreFavorite)
+		api.GET("/favorites/:id/history", favoritesHandler.GetHistory)
+		api.DELETE("/favorites/:id/permanent", favoritesHandler.PurgeFavorite)
 
 		// Stats API
 		api.GET("/stats", userHandler.GetStats)
+		api.GET("/stats/genres", userHandler.GetGenreStats)
+		api.GET("/stats/directors", userHandler.GetDirectorStats)
+
+		// Goals API
+		api.GET("/goals", goalsHandler.GetGoals)
+		api.POST("/goals", goalsHandler.CreateGoal)
+		api.DELETE("/goals/:id", goalsHandler.DeleteGoal)
+
+		// Picker API
+		api.GET("/picker", pickerHandler.Pick)
+		api.POST("/favorites/:id/snooze", pickerHandler.Snooze)
+
+		// Recommendations API
+		api.GET("/recommendations", recommendationHandler.GetRecommendations)
+		api.GET("/recommendations/similar-taste", recommendationHandler.GetTasteSuggestions)
+
+		// TV API
+		api.GET("/tv/search", showHandler.SearchShows)
+		api.GET("/tv/next", showHandler.GetNextEpisodes)
+		api.POST("/tv", showHandler.TrackShow)
+		api.DELETE("/tv/:id", showHandler.DeleteShow)
+		api.PATCH("/tv/:id/seasons/:season", showHandler.UpdateSeason)
+		api.PATCH("/tv/:id/episodes/:episode_id", showHandler.UpdateEpisode)
+
+		// Calendar API
+		api.GET("/calendar", calendarHandler.GetUpcoming)
+		api.POST("/calendar/token", calendarHandler.ResetToken)
+
+		// Notifications API
+		api.GET("/notifications", notificationHandler.ListNotifications)
+		api.GET("/notifications/unread", notificationHandler.GetUnreadCount)
+		api.POST("/notifications/read", notificationHandler.MarkAllRead)
+		api.POST("/notifications/:id/read", notificationHandler.MarkRead)
+		api.POST("/notifications/preferences", notificationHandler.UpdatePreferences)
+
+		// Webhooks API
+		api.GET("/webhooks", webhookHandler.ListWebhooks)
+		api.POST("/webhooks", webhookHandler.CreateWebhook)
+		api.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+		api.POST("/webhooks/:id/test", webhookHandler.SendTest)
+		api.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+
+		// Follows API
+		api.GET("/users/search", followHandler.SearchUsers)
+		api.GET("/follows", followHandler.ListFollows)
+		api.POST("/following", followHandler.Follow)
+		api.DELETE("/following/:user_id", followHandler.Unfollow)
+		api.POST("/followers/:user_id/accept", followHandler.AcceptFollower)
+		api.DELETE("/followers/:user_id", followHandler.RemoveFollower)
+		api.GET("/feed", followHandler.GetFeed)
+
+		// Recommended movies API
+		api.POST("/movies/:id/recommend", movieRecommendationHandler.Recommend)
+		api.GET("/recommended", movieRecommendationHandler.ListRecommended)
+		api.POST("/recommended/:id/accept", movieRecommendationHandler.Accept)
+		api.POST("/recommended/:id/decline", movieRecommendationHandler.Decline)
+
+		// List sharing API
+		api.PATCH("/lists/:id/visibility", publicPageHandler.SetListVisibility)
+		api.POST("/lists/:id/token", publicPageHandler.ResetListToken)
+
+		// Settings API
+		api.POST("/settings", settingsHandler.UpdateSettings)
+		api.GET("/providers", settingsHandler.GetProviders)
 	}
-}
\ No newline at end of file
+}
@@ -15,7 +15,9 @@ func NewAuthService() *AuthService {
 	return &AuthService{}
 }
 
-func (s *AuthService) Register(username, email, password string) (*models.User, error) {
+// Register creates a user. language is the UI language they signed up in,
+// usually negotiated from the browser; they can change it in settings.
+func (s *AuthService) Register(username, email, password, language string) (*models.User, error) {
 	if err := s.validateRegistration(username, email, password); err != nil {
 		return nil, err
 	}
@@ -23,6 +25,7 @@ func (s *AuthService) Register(username, email, password string) (*models.User,
 	user := &models.User{
 		Username: username,
 		Email:    email,
+		Language: language,
 	}
 
 	if err := user.SetPassword(password); err != nil {
@@ -47,7 +50,7 @@ func (s *AuthService) Login(username, password string) (*models.User, error) {
 
 	db := database.GetDB()
 	var user models.User
-	
+
 	err := db.Where("username = ? OR email = ?", username, username).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -66,7 +69,7 @@ func (s *AuthService) Login(username, password string) (*models.User, error) {
 func (s *AuthService) GetUserByID(id uint) (*models.User, error) {
 	db := database.GetDB()
 	var user models.User
-	
+
 	err := db.First(&user, id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -93,4 +96,4 @@ func (s *AuthService) validateRegistration(username, email, password string) err
 	}
 
 	return nil
-}
\ No newline at end of file
+}
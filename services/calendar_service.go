@@ -0,0 +1,342 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"movie-tracker/database"
+	"movie-tracker/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// releaseLookback is how long after its primary release a watchlist movie
+// keeps having its regional release dates refreshed: digital and physical
+// releases often come months after the theatrical one.
+const releaseLookback = 365 * 24 * time.Hour
+
+// CalendarEntry is an upcoming release of a movie on a user's watchlist.
+type CalendarEntry struct {
+	FavoriteID uint               `json:"favorite_id"`
+	TMDBId     int                `json:"tmdb_id"`
+	Title      string             `json:"title"`
+	PosterPath string             `json:"poster_path"`
+	Date       time.Time          `json:"date"`
+	Type       models.ReleaseType `json:"type"`
+	Region     string             `json:"region,omitempty"` // Empty for the primary release date
+	Note       string             `json:"note,omitempty"`
+}
+
+// CalendarDay is a cell of a month grid.
+type CalendarDay struct {
+	Date    time.Time
+	InMonth bool // False for the days of the previous and next months that fill the first and last weeks
+	Today   bool
+	Entries []CalendarEntry
+}
+
+// CalendarMonth is a month laid out in weeks starting on Monday.
+type CalendarMonth struct {
+	Month time.Time
+	Weeks [][]CalendarDay
+}
+
+// CalendarService builds the release calendar of a user's watchlist from
+// the regional release dates cached in movie_release_dates, which are only
+// fetched for the regions users have chosen.
+type CalendarService struct {
+	tmdbService *TMDBService
+}
+
+func NewCalendarService() *CalendarService {
+	return &CalendarService{
+		tmdbService: NewTMDBService(),
+	}
+}
+
+// Today returns the current date as midnight UTC, the way release dates are
+// stored.
+func Today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// GetUpcoming returns the releases on or after since of the movies on the
+// user's watchlist, soonest first. Movies with release dates in the user's
+// region get one entry per calendar release type; the others fall back to
+// their primary release date.
+func (s *CalendarService) GetUpcoming(user *models.User, since time.Time) ([]CalendarEntry, error) {
+	db := database.GetDB()
+
+	var favorites []models.FavoriteMovie
+	if err := db.Where("user_id = ? AND status = ?", user.ID, models.StatusToBe).Find(&favorites).Error; err != nil {
+		return nil, err
+	}
+	if len(favorites) == 0 {
+		return []CalendarEntry{}, nil
+	}
+
+	// Every known regional date, past ones included, so a movie already
+	// released in the region doesn't fall back to its primary date
+	regional := make(map[int][]models.MovieReleaseDate)
+	if user.Region != "" {
+		tmdbIDs := make([]int, 0, len(favorites))
+		for _, favorite := range favorites {
+			tmdbIDs = append(tmdbIDs, favorite.TMDBId)
+		}
+
+		var releases []models.MovieReleaseDate
+		err := db.Where("tmdb_id IN ? AND region = ? AND type IN ?", tmdbIDs, user.Region, models.CalendarReleaseTypes).
+			Find(&releases).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			regional[release.TMDBId] = append(regional[release.TMDBId], release)
+		}
+	}
+
+	entries := []CalendarEntry{}
+	for _, favorite := range favorites {
+		entry := CalendarEntry{
+			FavoriteID: favorite.ID,
+			TMDBId:     favorite.TMDBId,
+			Title:      favorite.Title,
+			PosterPath: favorite.PosterPath,
+		}
+
+		if releases, ok := regional[favorite.TMDBId]; ok {
+			for _, release := range releases {
+				if release.Date.Before(since) {
+					continue
+				}
+				entry.Date = release.Date
+				entry.Type = release.Type
+				entry.Region = release.Region
+				entry.Note = release.Note
+				entries = append(entries, entry)
+			}
+			continue
+		}
+
+		if favorite.ReleaseDate != nil && !favorite.ReleaseDate.Before(since) {
+			entry.Date = favorite.ReleaseDate.UTC()
+			entry.Type = models.ReleaseTypePrimary
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.Before(entries[j].Date)
+		}
+		if entries[i].Title != entries[j].Title {
+			return entries[i].Title < entries[j].Title
+		}
+		return entries[i].Type < entries[j].Type
+	})
+
+	return entries, nil
+}
+
+// CalendarMonths lays entries out in month grids, from the month of today
+// to the month of the last entry, at most maxMonths of them.
+func CalendarMonths(entries []CalendarEntry, today time.Time, maxMonths int) []CalendarMonth {
+	byDay := make(map[string][]CalendarEntry)
+	for _, entry := range entries {
+		day := entry.Date.UTC().Format("2006-01-02")
+		byDay[day] = append(byDay[day], entry)
+	}
+
+	first := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := first
+	if len(entries) > 0 {
+		lastDate := entries[len(entries)-1].Date
+		last = time.Date(lastDate.Year(), lastDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	var months []CalendarMonth
+	for month := first; !month.After(last) && len(months) < maxMonths; month = month.AddDate(0, 1, 0) {
+		// Back up to the Monday of the month's first week
+		day := month.AddDate(0, 0, -((int(month.Weekday()) + 6) % 7))
+
+		calendarMonth := CalendarMonth{Month: month}
+		for day.Before(month.AddDate(0, 1, 0)) {
+			week := make([]CalendarDay, 0, 7)
+			for i := 0; i < 7; i++ {
+				week = append(week, CalendarDay{
+					Date:    day,
+					InMonth: day.Month() == month.Month(),
+					Today:   day.Equal(today),
+					Entries: byDay[day.Format("2006-01-02")],
+				})
+				day = day.AddDate(0, 0, 1)
+			}
+			calendarMonth.Weeks = append(calendarMonth.Weeks, week)
+		}
+		months = append(months, calendarMonth)
+	}
+
+	return months
+}
+
+// Token returns the user's calendar feed token, creating it on first use.
+func (s *CalendarService) Token(user *models.User) (string, error) {
+	if user.CalendarToken != nil {
+		return *user.CalendarToken, nil
+	}
+	return s.ResetToken(user)
+}
+
+// ResetToken gives the user a new calendar feed token, so the previous feed
+// URL stops working.
+func (s *CalendarService) ResetToken(user *models.User) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+
+	db := database.GetDB()
+	if err := db.Model(user).Update("calendar_token", token).Error; err != nil {
+		return "", err
+	}
+
+	user.CalendarToken = &token
+	return token, nil
+}
+
+// GetUserByToken returns the user a calendar feed token belongs to.
+func (s *CalendarService) GetUserByToken(token string) (*models.User, error) {
+	db := database.GetDB()
+
+	var user models.User
+	if err := db.Where("calendar_token = ?", token).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// staleReleases is a watchlist movie whose release dates need fetching for
+// a region.
+type staleReleases struct {
+	TMDBId int
+	Region string
+}
+
+// RefreshStale refreshes up to batchSize (movie, region) pairs whose release
+// dates have never been fetched or are older than maxAge, least recently
+// tried first, so movies TMDB keeps failing on don't block the rest. Only
+// watchlist movies of users who chose a region, and which are unreleased or
+// were released less than a year ago, are considered. Each movie is fetched
+// once for all its regions, waiting delay between TMDB requests.
+func (s *CalendarService) RefreshStale(batchSize int, maxAge, delay time.Duration) error {
+	db := database.GetDB()
+
+	now := time.Now()
+	var stale []staleReleases
+	err := db.Raw(`
+		SELECT f.tmdb_id, u.region
+		FROM favorite_movies f
+		JOIN users u ON u.id = f.user_id
+		LEFT JOIN movie_release_syncs rs ON rs.tmdb_id = f.tmdb_id AND rs.region = u.region
+		WHERE f.deleted_at IS NULL AND f.status = ?
+			AND (f.release_date IS NULL OR f.release_date > ?)
+			AND u.deleted_at IS NULL AND u.region <> ''
+			AND (rs.synced_at IS NULL OR rs.synced_at < ?)
+		GROUP BY f.tmdb_id, u.region
+		ORDER BY MIN(rs.tried_at) NULLS FIRST, MIN(rs.synced_at) NULLS FIRST
+		LIMIT ?`, models.StatusToBe, now.Add(-releaseLookback), now.Add(-maxAge), batchSize).
+		Scan(&stale).Error
+	if err != nil {
+		return err
+	}
+
+	var tmdbIDs []int
+	regions := make(map[int][]string)
+	for _, row := range stale {
+		if _, ok := regions[row.TMDBId]; !ok {
+			tmdbIDs = append(tmdbIDs, row.TMDBId)
+		}
+		regions[row.TMDBId] = append(regions[row.TMDBId], row.Region)
+	}
+
+	for i, tmdbID := range tmdbIDs {
+		if i > 0 {
+			time.Sleep(delay)
+		}
+		triedAt := time.Now()
+		tries := make([]models.MovieReleaseSync, 0, len(regions[tmdbID]))
+		for _, region := range regions[tmdbID] {
+			tries = append(tries, models.MovieReleaseSync{TMDBId: tmdbID, Region: region, TriedAt: &triedAt})
+		}
+		err := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tmdb_id"}, {Name: "region"}},
+			DoUpdates: clause.AssignmentColumns([]string{"tried_at"}),
+		}).Create(&tries).Error
+		if err != nil {
+			return err
+		}
+		if err := s.RefreshMovie(tmdbID, regions[tmdbID]); err != nil {
+			log.Printf("Failed to refresh release dates for TMDB movie %d: %v", tmdbID, err)
+		}
+	}
+
+	return nil
+}
+
+// RefreshMovie replaces the cached release dates of tmdbID in each of
+// regions.
+func (s *CalendarService) RefreshMovie(tmdbID int, regions []string) error {
+	db := database.GetDB()
+
+	response, err := s.tmdbService.GetReleaseDates(tmdbID)
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]bool, len(regions))
+	for _, region := range regions {
+		wanted[region] = true
+	}
+
+	now := time.Now()
+	var releases []models.MovieReleaseDate
+	for _, result := range response.Results {
+		if !wanted[result.Region] {
+			continue
+		}
+		for _, release := range result.ReleaseDates {
+			releases = append(releases, models.MovieReleaseDate{
+				TMDBId: tmdbID,
+				Region: result.Region,
+				Type:   release.Type,
+				Date:   release.ReleaseDate.UTC().Truncate(24 * time.Hour),
+				Note:   release.Note,
+			})
+		}
+	}
+
+	syncs := make([]models.MovieReleaseSync, 0, len(regions))
+	for _, region := range regions {
+		syncs = append(syncs, models.MovieReleaseSync{TMDBId: tmdbID, Region: region, SyncedAt: &now})
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tmdb_id = ? AND region IN ?", tmdbID, regions).Delete(&models.MovieReleaseDate{}).Error; err != nil {
+			return err
+		}
+		if len(releases) > 0 {
+			// The same type can be listed twice on one day with different notes
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&releases).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tmdb_id"}, {Name: "region"}},
+			DoUpdates: clause.AssignmentColumns([]string{"synced_at"}),
+		}).Create(&syncs).Error
+	})
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DiscoverMaxPage is the last page TMDB's discover endpoint serves.
+	DiscoverMaxPage = 500
+	// ratedMinVotes is applied when sorting by rating without a minimum
+	// vote count, so movies rated 10 by two people don't fill the top.
+	ratedMinVotes = 200
+)
+
+// DiscoverSort is an order the discover results can be sorted in.
+type DiscoverSort struct {
+	Value string `json:"value"` // TMDB's sort_by value
+	Label string `json:"label"` // English, translated by the templates
+}
+
+// DiscoverSortOptions are the supported sort orders, the default first.
+var DiscoverSortOptions = []DiscoverSort{
+	{Value: "popularity.desc", Label: "Most popular"},
+	{Value: "vote_average.desc", Label: "Highest rated"},
+	{Value: "vote_count.desc", Label: "Most voted"},
+	{Value: "primary_release_date.desc", Label: "Newest"},
+	{Value: "primary_release_date.asc", Label: "Oldest"},
+	{Value: "revenue.desc", Label: "Highest grossing"},
+}
+
+// discoverLanguages are the original languages offered as a filter.
+var discoverLanguages = []string{"en", "es", "fr", "de", "it", "pt", "ja", "ko", "zh", "hi", "sv", "da", "no", "ru", "tr"}
+
+// DiscoverLanguage is an original language offered as a filter.
+type DiscoverLanguage struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// DiscoverLanguages returns the original languages offered as a filter with
+// their names in lang.
+func DiscoverLanguages(lang string) []DiscoverLanguage {
+	languages := make([]DiscoverLanguage, 0, len(discoverLanguages))
+	for _, code := range discoverLanguages {
+		languages = append(languages, DiscoverLanguage{Code: code, Name: languageName(code, lang)})
+	}
+	return languages
+}
+
+// DiscoverFilter narrows down the movies returned by TMDB's discover
+// endpoint. Nil and empty fields are not applied.
+type DiscoverFilter struct {
+	GenreIDs       []int // The movie must have every one of these genres
+	YearFrom       *int
+	YearTo         *int
+	MinRuntime     *int
+	MaxRuntime     *int
+	Language       string // ISO 639-1 original language
+	MinVoteCount   *int
+	MinVoteAverage *float64
+	// SortBy is one of DiscoverSortOptions; anything else sorts by
+	// popularity
+	SortBy string
+}
+
+// IsZero reports whether no filter or sort order is set.
+func (f DiscoverFilter) IsZero() bool {
+	return len(f.Values()) == 0
+}
+
+// HasGenre reports whether genreID is one of the required genres.
+func (f DiscoverFilter) HasGenre(genreID int) bool {
+	for _, id := range f.GenreIDs {
+		if id == genreID {
+			return true
+		}
+	}
+	return false
+}
+
+// Sort returns the sort order to use, falling back to the default.
+func (f DiscoverFilter) Sort() string {
+	for _, option := range DiscoverSortOptions {
+		if option.Value == f.SortBy {
+			return f.SortBy
+		}
+	}
+	return DiscoverSortOptions[0].Value
+}
+
+// Values encodes the filter as the query string the search page and the
+// discover API accept, so a filtered search can be shared as a URL.
+func (f DiscoverFilter) Values() url.Values {
+	values := url.Values{}
+	for _, genreID := range f.GenreIDs {
+		values.Add("genre", strconv.Itoa(genreID))
+	}
+	setInt(values, "year_from", f.YearFrom)
+	setInt(values, "year_to", f.YearTo)
+	setInt(values, "min_runtime", f.MinRuntime)
+	setInt(values, "max_runtime", f.MaxRuntime)
+	if f.Language != "" {
+		values.Set("language", f.Language)
+	}
+	setInt(values, "min_votes", f.MinVoteCount)
+	if f.MinVoteAverage != nil {
+		values.Set("min_rating", strconv.FormatFloat(*f.MinVoteAverage, 'f', -1, 64))
+	}
+	if f.SortBy != "" && f.Sort() == f.SortBy {
+		values.Set("sort", f.SortBy)
+	}
+	return values
+}
+
+// tmdbParams translates the filter into TMDB's discover parameters.
+func (f DiscoverFilter) tmdbParams() url.Values {
+	params := url.Values{
+		"sort_by":       {f.Sort()},
+		"include_adult": {"false"},
+	}
+
+	if len(f.GenreIDs) > 0 {
+		ids := make([]string, 0, len(f.GenreIDs))
+		for _, genreID := range f.GenreIDs {
+			ids = append(ids, strconv.Itoa(genreID))
+		}
+		// Commas mean AND in TMDB's with_genres
+		params.Set("with_genres", strings.Join(ids, ","))
+	}
+	if f.YearFrom != nil {
+		params.Set("primary_release_date.gte", fmt.Sprintf("%04d-01-01", *f.YearFrom))
+	}
+	if f.YearTo != nil {
+		params.Set("primary_release_date.lte", fmt.Sprintf("%04d-12-31", *f.YearTo))
+	}
+	setInt(params, "with_runtime.gte", f.MinRuntime)
+	setInt(params, "with_runtime.lte", f.MaxRuntime)
+	if f.Language != "" {
+		params.Set("with_original_language", f.Language)
+	}
+	setInt(params, "vote_count.gte", f.MinVoteCount)
+	if f.MinVoteCount == nil && f.Sort() == "vote_average.desc" {
+		params.Set("vote_count.gte", strconv.Itoa(ratedMinVotes))
+	}
+	if f.MinVoteAverage != nil {
+		params.Set("vote_average.gte", strconv.FormatFloat(*f.MinVoteAverage, 'f', -1, 64))
+	}
+
+	return params
+}
+
+func setInt(values url.Values, key string, value *int) {
+	if value != nil {
+		values.Set(key, strconv.Itoa(*value))
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"log"
+	"movie-tracker/models"
+	"sync"
+	"time"
+)
+
+// EventType identifies a domain event published by the services.
+type EventType string
+
+const (
+	EventFavoriteAdded         EventType = "favorite.added"
+	EventFavoriteStatusChanged EventType = "favorite.status_changed"
+	EventFavoriteRated         EventType = "favorite.rated"
+	EventFavoriteDeleted       EventType = "favorite.deleted"
+	EventFavoriteRestored      EventType = "favorite.restored"
+)
+
+// Event describes something that happened to a user's favorite. Events are
+// published after the change has been committed.
+type Event struct {
+	Type       EventType            `json:"type"`
+	UserID     uint                 `json:"user_id"`
+	Favorite   models.FavoriteMovie `json:"favorite"`
+	FromStatus models.Status        `json:"from_status,omitempty"`
+	ToStatus   models.Status        `json:"to_status,omitempty"`
+	Source     models.ChangeSource  `json:"source"`
+	OccurredAt time.Time            `json:"occurred_at"`
+}
+
+// EventHandler reacts to a published event. Handlers run synchronously on
+// the publishing goroutine, so slow work should be handed off.
+type EventHandler func(Event)
+
+var eventBus = struct {
+	sync.RWMutex
+	handlers map[EventType][]EventHandler
+}{handlers: make(map[EventType][]EventHandler)}
+
+// Subscribe registers handler for every event of the given types.
+func Subscribe(handler EventHandler, eventTypes ...EventType) {
+	eventBus.Lock()
+	defer eventBus.Unlock()
+
+	for _, eventType := range eventTypes {
+		eventBus.handlers[eventType] = append(eventBus.handlers[eventType], handler)
+	}
+}
+
+// Publish delivers events to their subscribers. A panicking handler is
+// logged and does not affect the others.
+func Publish(events ...Event) {
+	for _, event := range events {
+		if event.OccurredAt.IsZero() {
+			event.OccurredAt = time.Now()
+		}
+
+		eventBus.RLock()
+		handlers := eventBus.handlers[event.Type]
+		eventBus.RUnlock()
+
+		for _, handler := range handlers {
+			dispatch(handler, event)
+		}
+	}
+}
+
+func dispatch(handler EventHandler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Event handler for %s panicked: %v", event.Type, r)
+		}
+	}()
+
+	handler(event)
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"movie-tracker/database"
+	"movie-tracker/models"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// maxTagName and maxListName are the longest tag and list names, in
+// characters, as stored by models.Tag and models.MovieList.
+const (
+	maxTagName  = 50
+	maxListName = 100
+)
+
+type BulkAction string
+
+const (
+	BulkSetStatus      BulkAction = "status"
+	BulkSetRating      BulkAction = "rating"
+	BulkAddTag         BulkAction = "add_tag"
+	BulkRemoveTag      BulkAction = "remove_tag"
+	BulkAddToList      BulkAction = "add_to_list"
+	BulkRemoveFromList BulkAction = "remove_from_list"
+	BulkDelete         BulkAction = "delete"
+)
+
+// BulkOperation applies one action to many favorites. Value holds the status,
+// rating, tag name or list name depending on Action.
+type BulkOperation struct {
+	IDs    []uint     `json:"ids" form:"ids"`
+	Action BulkAction `json:"action" form:"action"`
+	Value  string     `json:"value" form:"value"`
+}
+
+// BulkResult reports the outcome of a bulk operation for a single favorite.
+type BulkResult struct {
+	ID      uint   `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BulkUpdate applies op to every listed favorite owned by userID inside a
+// single transaction. Favorites that don't exist are reported as failed
+// without aborting the rest; database errors roll everything back.
+func (s *FavoritesService) BulkUpdate(userID uint, op BulkOperation, source models.ChangeSource) ([]BulkResult, error) {
+	if len(op.IDs) == 0 {
+		return nil, errors.New("no movies selected")
+	}
+
+	value := strings.TrimSpace(op.Value)
+	var updates map[string]interface{}
+
+	switch op.Action {
+	case BulkSetStatus:
+		status := models.Status(value)
+		if !models.IsValidStatus(status) {
+			return nil, errors.New("invalid status")
+		}
+		updates = map[string]interface{}{"status": status}
+	case BulkSetRating:
+		rating, err := strconv.Atoi(value)
+		if err != nil || rating < 1 || rating > 10 {
+			return nil, errors.New("rating must be between 1 and 10")
+		}
+		updates = map[string]interface{}{"rating": rating}
+	case BulkAddTag, BulkRemoveTag:
+		if value == "" {
+			return nil, errors.New("a tag or list name is required")
+		}
+		if utf8.RuneCountInString(value) > maxTagName {
+			return nil, fmt.Errorf("tag names can't be longer than %d characters", maxTagName)
+		}
+	case BulkAddToList, BulkRemoveFromList:
+		if value == "" {
+			return nil, errors.New("a tag or list name is required")
+		}
+		if utf8.RuneCountInString(value) > maxListName {
+			return nil, fmt.Errorf("list names can't be longer than %d characters", maxListName)
+		}
+		if slugify(value) == "" {
+			return nil, errors.New("invalid list name")
+		}
+	case BulkDelete:
+	default:
+		return nil, errors.New("invalid bulk action")
+	}
+
+	// Each favorite is handled once, however many times it was listed
+	ids := make([]uint, 0, len(op.IDs))
+	seen := make(map[uint]bool, len(op.IDs))
+	for _, id := range op.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	db := database.GetDB()
+	results := make([]BulkResult, 0, len(ids))
+	var events []Event
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var favorites []models.FavoriteMovie
+		if err := tx.Where("id IN ? AND user_id = ?", ids, userID).Find(&favorites).Error; err != nil {
+			return err
+		}
+
+		byID := make(map[uint]*models.FavoriteMovie, len(favorites))
+		for i := range favorites {
+			byID[favorites[i].ID] = &favorites[i]
+		}
+
+		var tag *models.Tag
+		var list *models.MovieList
+		var err error
+		switch op.Action {
+		case BulkAddTag, BulkRemoveTag:
+			if tag, err = findOrCreateTag(tx, userID, value); err != nil {
+				return err
+			}
+		case BulkAddToList, BulkRemoveFromList:
+			if list, err = findOrCreateList(tx, userID, value); err != nil {
+				return err
+			}
+		}
+
+		for _, id := range ids {
+			favorite, ok := byID[id]
+			if !ok {
+				results = append(results, BulkResult{ID: id, Error: "favorite movie not found"})
+				continue
+			}
+
+			switch op.Action {
+			case BulkSetStatus, BulkSetRating:
+				// Copy so watched_at added for one movie doesn't leak into the next
+				itemUpdates := make(map[string]interface{}, len(updates))
+				for k, v := range updates {
+					itemUpdates[k] = v
+				}
+				var itemEvents []Event
+				itemEvents, err = s.applyUpdates(tx, favorite, itemUpdates, source)
+				var transitionErr *TransitionError
+				if errors.As(err, &transitionErr) {
+					// Disallowed transitions only fail this item
+					results = append(results, BulkResult{ID: id, Error: err.Error()})
+					continue
+				}
+				if err == nil {
+					events = append(events, itemEvents...)
+				}
+			case BulkAddTag:
+				err = tx.Model(favorite).Association("Tags").Append(tag)
+			case BulkRemoveTag:
+				err = tx.Model(favorite).Association("Tags").Delete(tag)
+			case BulkAddToList:
+				err = tx.Model(favorite).Association("Lists").Append(list)
+			case BulkRemoveFromList:
+				err = tx.Model(favorite).Association("Lists").Delete(list)
+			case BulkDelete:
+				err = tx.Delete(favorite).Error
+				events = append(events, Event{
+					Type:     EventFavoriteDeleted,
+					UserID:   userID,
+					Favorite: *favorite,
+					Source:   source,
+				})
+			}
+			if err != nil {
+				return err
+			}
+
+			results = append(results, BulkResult{ID: id, Success: true})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	Publish(events...)
+
+	return results, nil
+}
+
+func findOrCreateTag(tx *gorm.DB, userID uint, name string) (*models.Tag, error) {
+	tag := models.Tag{UserID: userID, Name: strings.ToLower(name)}
+	if err := tx.Where(tag).FirstOrCreate(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func findOrCreateList(tx *gorm.DB, userID uint, name string) (*models.MovieList, error) {
+	slug := slugify(name)
+	if slug == "" {
+		return nil, errors.New("invalid list name")
+	}
+
+	list := models.MovieList{UserID: userID, Slug: slug}
+	if err := tx.Where(list).Attrs(models.MovieList{Name: name}).FirstOrCreate(&list).Error; err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// slugify lowercases name and joins its letters and digits with dashes
+// ("Pelis de Terror 2024" -> "pelis-de-terror-2024").
+func slugify(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
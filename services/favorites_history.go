@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"movie-tracker/database"
+	"movie-tracker/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// historyFields are the FavoriteMovie columns whose changes are recorded.
+var historyFields = []string{"status", "rating", "notes", "recommended_by", "watched_at", "abandon_reason", "priority", "deleted_at"}
+
+// GetFavoriteHistory returns the change timeline of a favorite, oldest first.
+func (s *FavoritesService) GetFavoriteHistory(id, userID uint) ([]models.FavoriteHistory, error) {
+	if _, err := s.GetFavoriteByID(id, userID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB()
+	var history []models.FavoriteHistory
+
+	err := db.Where("favorite_movie_id = ? AND user_id = ?", id, userID).
+		Order("created_at ASC, id ASC").
+		Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// recordCreation stores the initial values of a newly added favorite.
+func recordCreation(tx *gorm.DB, favorite *models.FavoriteMovie, source models.ChangeSource) error {
+	var entries []models.FavoriteHistory
+	for _, field := range historyFields {
+		value := favoriteFieldValue(favorite, field)
+		if value == "" {
+			continue
+		}
+		entries = append(entries, models.FavoriteHistory{
+			FavoriteMovieID: favorite.ID,
+			UserID:          favorite.UserID,
+			Field:           field,
+			NewValue:        value,
+			Source:          source,
+		})
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+	return tx.Create(&entries).Error
+}
+
+// recordChanges stores one history row per tracked field in updates whose
+// value differs from the current one on favorite. It must run before the
+// updates are applied.
+func recordChanges(tx *gorm.DB, favorite *models.FavoriteMovie, updates map[string]interface{}, source models.ChangeSource) error {
+	var entries []models.FavoriteHistory
+	for _, field := range historyFields {
+		value, ok := updates[field]
+		if !ok {
+			continue
+		}
+
+		oldValue := favoriteFieldValue(favorite, field)
+		newValue := formatHistoryValue(value)
+		if oldValue == newValue {
+			continue
+		}
+
+		entries = append(entries, models.FavoriteHistory{
+			FavoriteMovieID: favorite.ID,
+			UserID:          favorite.UserID,
+			Field:           field,
+			OldValue:        oldValue,
+			NewValue:        newValue,
+			Source:          source,
+		})
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+	return tx.Create(&entries).Error
+}
+
+func favoriteFieldValue(favorite *models.FavoriteMovie, field string) string {
+	switch field {
+	case "status":
+		return string(favorite.Status)
+	case "rating":
+		return formatHistoryValue(favorite.Rating)
+	case "notes":
+		return favorite.Notes
+	case "recommended_by":
+		return favorite.RecommendedBy
+	case "watched_at":
+		return formatHistoryValue(favorite.WatchedAt)
+	case "abandon_reason":
+		return favorite.AbandonReason
+	case "priority":
+		if favorite.Priority == models.PriorityNormal {
+			return ""
+		}
+		return formatHistoryValue(favorite.Priority)
+	case "deleted_at":
+		if !favorite.DeletedAt.Valid {
+			return ""
+		}
+		return formatHistoryValue(favorite.DeletedAt.Time)
+	}
+	return ""
+}
+
+func formatHistoryValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case *int:
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprintf("%d", *v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -2,63 +2,180 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"movie-tracker/database"
 	"movie-tracker/models"
+	"strings"
 	"time"
+	"unicode"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type FavoritesService struct{}
+type FavoritesService struct {
+	genreService *GenreService
+}
 
 func NewFavoritesService() *FavoritesService {
-	return &FavoritesService{}
+	return &FavoritesService{
+		genreService: NewGenreService(),
+	}
 }
 
-func (s *FavoritesService) AddToFavorites(userID uint, tmdbMovie *models.TMDBMovie, status models.Status, rating *int, notes, recommendedBy string) (*models.FavoriteMovie, error) {
-	db := database.GetDB()
+// FavoritesFilter narrows down the movies returned by GetUserFavorites.
+// Nil fields are not applied.
+type FavoritesFilter struct {
+	Status  *models.Status
+	GenreID *int
+	// Tag and List match a tag name or a list slug
+	Tag  string
+	List string
+	// Query is free text matched against title, original title, overview,
+	// notes and recommended_by. Results are ranked by relevance.
+	Query string
+	// Streaming keeps only the movies that can be streamed right now on one
+	// of the given services. Providers are only cached for the watchlist.
+	Streaming *StreamingServices
+}
 
-	var releaseDate *time.Time
-	if tmdbMovie.ReleaseDate != "" {
-		if t, err := time.Parse("2006-01-02", tmdbMovie.ReleaseDate); err == nil {
-			releaseDate = &t
-		}
+// Sortable reports whether the filter returns the watchlist in its own order,
+// so that it can be reordered by hand.
+func (f FavoritesFilter) Sortable() bool {
+	return f.Status != nil && *f.Status == models.StatusToBe && buildSearchQuery(f.Query) == ""
+}
+
+func (s *FavoritesService) AddToFavorites(userID uint, tmdbMovie *models.TMDBMovie, status models.Status, rating *int, notes, recommendedBy string, source models.ChangeSource) (*models.FavoriteMovie, error) {
+	var favorite *models.FavoriteMovie
+	var event Event
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		favorite, event, err = s.createFavorite(tx, userID, tmdbMovie, status, rating, notes, recommendedBy, source)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	Publish(event)
+
+	return favorite, nil
+}
+
+// createFavorite adds tmdbMovie to userID's favorites using tx, at the top of
+// the watchlist, and returns the event to publish once the transaction
+// commits.
+func (s *FavoritesService) createFavorite(tx *gorm.DB, userID uint, tmdbMovie *models.TMDBMovie, status models.Status, rating *int, notes, recommendedBy string, source models.ChangeSource) (*models.FavoriteMovie, Event, error) {
+	var runtime *int
+	if tmdbMovie.Runtime > 0 {
+		runtime = &tmdbMovie.Runtime
 	}
 
 	favorite := &models.FavoriteMovie{
-		UserID:        userID,
-		TMDBId:        tmdbMovie.ID,
-		Title:         tmdbMovie.Title,
-		Overview:      tmdbMovie.Overview,
-		ReleaseDate:   releaseDate,
-		PosterPath:    tmdbMovie.PosterPath,
-		GenreIDs:      models.IntArray(tmdbMovie.GenreIDs),
-		Status:        status,
-		Rating:        rating,
-		Notes:         notes,
-		RecommendedBy: recommendedBy,
-	}
-
-	if err := db.Create(favorite).Error; err != nil {
+		UserID:           userID,
+		TMDBId:           tmdbMovie.ID,
+		Title:            tmdbMovie.Title,
+		Overview:         tmdbMovie.Overview,
+		ReleaseDate:      parseReleaseDate(tmdbMovie.ReleaseDate),
+		PosterPath:       tmdbMovie.PosterPath,
+		BackdropPath:     tmdbMovie.BackdropPath,
+		OriginalTitle:    tmdbMovie.OriginalTitle,
+		VoteAverage:      tmdbMovie.VoteAverage,
+		GenreIDs:         models.IntArray(tmdbMovie.GenreIDs),
+		OriginalLanguage: tmdbMovie.OriginalLanguage,
+		Runtime:          runtime,
+		Status:           status,
+		Rating:           rating,
+		Notes:            notes,
+		RecommendedBy:    recommendedBy,
+	}
+
+	if err := applyInitialStatus(favorite); err != nil {
+		return nil, Event{}, err
+	}
+
+	var trashed int64
+	err := tx.Unscoped().Model(&models.FavoriteMovie{}).
+		Where("user_id = ? AND tmdb_id = ? AND deleted_at IS NOT NULL", userID, tmdbMovie.ID).
+		Count(&trashed).Error
+	if err != nil {
+		return nil, Event{}, err
+	}
+	if trashed > 0 {
+		return nil, Event{}, ErrInTrash
+	}
+
+	// New movies go to the top of the watchlist, like the newest-first order they used to have
+	top, err := topPosition(tx, userID)
+	if err != nil {
+		return nil, Event{}, err
+	}
+	favorite.Position = top
+
+	if err := tx.Create(favorite).Error; err != nil {
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
-			return nil, errors.New("movie is already in favorites")
+			return nil, Event{}, errors.New("movie is already in favorites")
 		}
-		return nil, err
+		return nil, Event{}, err
+	}
+	if err := recordCreation(tx, favorite, source); err != nil {
+		return nil, Event{}, err
 	}
 
-	return favorite, nil
+	return favorite, Event{
+		Type:     EventFavoriteAdded,
+		UserID:   userID,
+		Favorite: *favorite,
+		ToStatus: favorite.Status,
+		Source:   source,
+	}, nil
 }
 
-func (s *FavoritesService) GetUserFavorites(userID uint, status *models.Status, offset, limit int) ([]models.FavoriteMovie, error) {
+func (s *FavoritesService) GetUserFavorites(userID uint, filter FavoritesFilter, offset, limit int) ([]models.FavoriteMovie, error) {
 	db := database.GetDB()
 	var favorites []models.FavoriteMovie
 
-	query := db.Where("user_id = ?", userID)
-	if status != nil {
-		query = query.Where("status = ?", *status)
+	query := db.Preload("Tags").Preload("Lists").Where("user_id = ?", userID)
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.GenreID != nil {
+		query = query.Where("genre_ids @> ?::jsonb", fmt.Sprintf("[%d]", *filter.GenreID))
+	}
+
+	if filter.Tag != "" {
+		query = query.Where(`id IN (
+			SELECT fmt.favorite_movie_id FROM favorite_movie_tags fmt
+			JOIN tags ON tags.id = fmt.tag_id
+			WHERE tags.user_id = ? AND tags.name = ?)`, userID, filter.Tag)
+	}
+	if filter.List != "" {
+		query = query.Where(`id IN (
+			SELECT fml.favorite_movie_id FROM favorite_movie_lists fml
+			JOIN movie_lists ON movie_lists.id = fml.movie_list_id
+			WHERE movie_lists.user_id = ? AND movie_lists.slug = ?)`, userID, filter.List)
+	}
+	if filter.Streaming != nil {
+		query = query.Where(`tmdb_id IN (
+			SELECT tmdb_id FROM movie_providers
+			WHERE region = ? AND provider_id IN ? AND type IN ?)`,
+			filter.Streaming.Region, filter.Streaming.ProviderIDs, models.StreamingProviderTypes)
+	}
+
+	if tsQuery := buildSearchQuery(filter.Query); tsQuery != "" {
+		query = query.
+			Where("search_vector @@ to_tsquery('simple', immutable_unaccent(?))", tsQuery).
+			Clauses(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "ts_rank(search_vector, to_tsquery('simple', immutable_unaccent(?))) DESC, added_at DESC",
+				Vars:               []interface{}{tsQuery},
+				WithoutParentheses: true,
+			}})
+	} else if filter.Sortable() {
+		query = query.Order("position ASC, added_at DESC")
+	} else {
+		query = query.Order("added_at DESC")
 	}
 
-	query = query.Order("added_at DESC")
 	if limit > 0 {
 		query = query.Offset(offset).Limit(limit)
 	}
@@ -67,9 +184,37 @@ func (s *FavoritesService) GetUserFavorites(userID uint, status *models.Status,
 		return nil, err
 	}
 
+	for i := range favorites {
+		favorites[i].GenreNames = s.genreService.GetGenreNames(favorites[i].GenreIDs)
+	}
+
 	return favorites, nil
 }
 
+// GetStatusesByTMDBIDs returns the user's status for each of tmdbIDs they
+// track. Untracked movies are missing from the map.
+func (s *FavoritesService) GetStatusesByTMDBIDs(userID uint, tmdbIDs []int) (map[int]models.Status, error) {
+	statuses := make(map[int]models.Status)
+	if len(tmdbIDs) == 0 {
+		return statuses, nil
+	}
+
+	db := database.GetDB()
+	var rows []models.FavoriteMovie
+	err := db.Select("tmdb_id, status").
+		Where("user_id = ? AND tmdb_id IN ?", userID, tmdbIDs).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		statuses[row.TMDBId] = row.Status
+	}
+
+	return statuses, nil
+}
+
 func (s *FavoritesService) GetFavoriteByID(id, userID uint) (*models.FavoriteMovie, error) {
 	db := database.GetDB()
 	var favorite models.FavoriteMovie
@@ -81,39 +226,159 @@ func (s *FavoritesService) GetFavoriteByID(id, userID uint) (*models.FavoriteMov
 		return nil, err
 	}
 
+	favorite.GenreNames = s.genreService.GetGenreNames(favorite.GenreIDs)
+
 	return &favorite, nil
 }
 
-func (s *FavoritesService) UpdateFavorite(id, userID uint, updates map[string]interface{}) (*models.FavoriteMovie, error) {
-	db := database.GetDB()
-	
+func (s *FavoritesService) UpdateFavorite(id, userID uint, updates map[string]interface{}, source models.ChangeSource) (*models.FavoriteMovie, error) {
 	favorite, err := s.GetFavoriteByID(id, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	if status, ok := updates["status"]; ok && status == models.StatusWatched {
-		if favorite.WatchedAt == nil {
-			now := time.Now()
-			updates["watched_at"] = now
+	return s.saveUpdates(favorite, updates, source)
+}
+
+// saveUpdates applies updates to an already loaded favorite in a transaction
+// and publishes the resulting events once it commits.
+func (s *FavoritesService) saveUpdates(favorite *models.FavoriteMovie, updates map[string]interface{}, source models.ChangeSource) (*models.FavoriteMovie, error) {
+	var events []Event
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		events, err = s.applyUpdates(tx, favorite, updates, source)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	Publish(events...)
+
+	return favorite, nil
+}
+
+// applyUpdates writes updates to favorite using tx. Status changes go through
+// the transition rules in models.StatusDefinitions, every change is recorded
+// in the favorite's history, and the resulting events are returned so the
+// caller can publish them once the transaction commits.
+func (s *FavoritesService) applyUpdates(tx *gorm.DB, favorite *models.FavoriteMovie, updates map[string]interface{}, source models.ChangeSource) ([]Event, error) {
+	fromStatus := favorite.Status
+	fromRating := formatHistoryValue(favorite.Rating)
+
+	if value, ok := updates["status"]; ok {
+		status := models.Status(fmt.Sprint(value))
+		updates["status"] = status
+		if err := applyTransition(favorite, status, updates); err != nil {
+			return nil, err
 		}
 	}
 
-	if err := db.Model(favorite).Updates(updates).Error; err != nil {
+	if entersWatchlist(favorite, updates) {
+		top, err := topPosition(tx, favorite.UserID)
+		if err != nil {
+			return nil, err
+		}
+		updates["position"] = top
+	}
+
+	if err := recordChanges(tx, favorite, updates, source); err != nil {
 		return nil, err
 	}
 
-	return favorite, nil
+	if err := tx.Model(favorite).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	if favorite.Status != fromStatus {
+		events = append(events, Event{
+			Type:       EventFavoriteStatusChanged,
+			UserID:     favorite.UserID,
+			Favorite:   *favorite,
+			FromStatus: fromStatus,
+			ToStatus:   favorite.Status,
+			Source:     source,
+		})
+	}
+	if formatHistoryValue(favorite.Rating) != fromRating {
+		events = append(events, Event{
+			Type:     EventFavoriteRated,
+			UserID:   favorite.UserID,
+			Favorite: *favorite,
+			Source:   source,
+		})
+	}
+
+	return events, nil
 }
 
-func (s *FavoritesService) UpdateStatus(id, userID uint, status models.Status) (*models.FavoriteMovie, error) {
+// UpdateStatus moves a favorite to status. reason is stored for statuses that
+// carry one (such as abandonada) and ignored otherwise.
+func (s *FavoritesService) UpdateStatus(id, userID uint, status models.Status, reason string, source models.ChangeSource) (*models.FavoriteMovie, error) {
 	updates := map[string]interface{}{
 		"status": status,
 	}
-	return s.UpdateFavorite(id, userID, updates)
+	if def, ok := models.GetStatusDefinition(status); ok && def.HasReason {
+		updates["abandon_reason"] = strings.TrimSpace(reason)
+	}
+	return s.UpdateFavorite(id, userID, updates, source)
+}
+
+// UpdateProgress records how many minutes of a favorite have been watched.
+// A movie that is still to be watched is moved to viendo; other statuses
+// must track progress.
+func (s *FavoritesService) UpdateProgress(id, userID uint, minutes int, source models.ChangeSource) (*models.FavoriteMovie, error) {
+	favorite, err := s.GetFavoriteByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateProgress(favorite, minutes, source)
+}
+
+// UpdateProgressPercent records progress as a percentage of the movie's
+// runtime, which must be known.
+func (s *FavoritesService) UpdateProgressPercent(id, userID uint, percent int, source models.ChangeSource) (*models.FavoriteMovie, error) {
+	if percent < 0 || percent > 100 {
+		return nil, errors.New("percent must be between 0 and 100")
+	}
+
+	favorite, err := s.GetFavoriteByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if favorite.Runtime == nil || *favorite.Runtime <= 0 {
+		return nil, errors.New("runtime unknown, report progress in minutes")
+	}
+
+	return s.updateProgress(favorite, *favorite.Runtime*percent/100, source)
 }
 
-func (s *FavoritesService) UpdateRating(id, userID uint, rating int) (*models.FavoriteMovie, error) {
+func (s *FavoritesService) updateProgress(favorite *models.FavoriteMovie, minutes int, source models.ChangeSource) (*models.FavoriteMovie, error) {
+	if minutes < 0 {
+		return nil, errors.New("minutes must not be negative")
+	}
+	if favorite.Runtime != nil && *favorite.Runtime > 0 && minutes > *favorite.Runtime {
+		return nil, fmt.Errorf("minutes must not exceed the runtime of %d", *favorite.Runtime)
+	}
+
+	updates := map[string]interface{}{
+		"progress_minutes": minutes,
+	}
+
+	def, _ := models.GetStatusDefinition(favorite.Status)
+	if !def.TracksProgress {
+		if favorite.Status != models.StatusToBe {
+			return nil, &TransitionError{message: fmt.Sprintf("progress cannot be tracked for %s movies", def.Label)}
+		}
+		updates["status"] = models.StatusWatching
+	}
+
+	return s.saveUpdates(favorite, updates, source)
+}
+
+func (s *FavoritesService) UpdateRating(id, userID uint, rating int, source models.ChangeSource) (*models.FavoriteMovie, error) {
 	if rating < 1 || rating > 10 {
 		return nil, errors.New("rating must be between 1 and 10")
 	}
@@ -121,20 +386,28 @@ func (s *FavoritesService) UpdateRating(id, userID uint, rating int) (*models.Fa
 	updates := map[string]interface{}{
 		"rating": rating,
 	}
-	return s.UpdateFavorite(id, userID, updates)
+	return s.UpdateFavorite(id, userID, updates, source)
 }
 
-func (s *FavoritesService) DeleteFavorite(id, userID uint) error {
+func (s *FavoritesService) DeleteFavorite(id, userID uint, source models.ChangeSource) error {
 	db := database.GetDB()
-	
-	result := db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.FavoriteMovie{})
-	if result.Error != nil {
-		return result.Error
+
+	favorite, err := s.GetFavoriteByID(id, userID)
+	if err != nil {
+		return err
 	}
-	if result.RowsAffected == 0 {
-		return errors.New("favorite movie not found")
+
+	if err := db.Delete(favorite).Error; err != nil {
+		return err
 	}
 
+	Publish(Event{
+		Type:     EventFavoriteDeleted,
+		UserID:   userID,
+		Favorite: *favorite,
+		Source:   source,
+	})
+
 	return nil
 }
 
@@ -146,17 +419,99 @@ func (s *FavoritesService) GetUserStats(userID uint) (map[string]int, error) {
 	db.Model(&models.FavoriteMovie{}).Where("user_id = ?", userID).Count(&total)
 	stats["total"] = int(total)
 
-	var toBe int64
-	db.Model(&models.FavoriteMovie{}).Where("user_id = ? AND status = ?", userID, models.StatusToBe).Count(&toBe)
-	stats["por_ver"] = int(toBe)
+	for _, def := range models.StatusDefinitions {
+		var count int64
+		db.Model(&models.FavoriteMovie{}).Where("user_id = ? AND status = ?", userID, def.Status).Count(&count)
+		stats[string(def.Status)] = int(count)
+	}
 
-	var watched int64
-	db.Model(&models.FavoriteMovie{}).Where("user_id = ? AND status = ?", userID, models.StatusWatched).Count(&watched)
-	stats["vista"] = int(watched)
+	var movieMinutes int
+	err := db.Model(&models.FavoriteMovie{}).
+		Select("COALESCE(SUM(runtime), 0)").
+		Where("user_id = ? AND status = ?", userID, models.StatusWatched).
+		Scan(&movieMinutes).Error
+	if err != nil {
+		return nil, err
+	}
+	stats["movie_hours"] = movieMinutes / 60
 
-	var recommended int64
-	db.Model(&models.FavoriteMovie{}).Where("user_id = ? AND status = ?", userID, models.StatusRecommended).Count(&recommended)
-	stats["recomendada"] = int(recommended)
+	episodes, episodeMinutes, err := episodeStats(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	stats["episodes_watched"] = episodes
+	stats["episode_hours"] = episodeMinutes / 60
 
 	return stats, nil
-}
\ No newline at end of file
+}
+
+// GetGenreStats counts the user's movies per genre, most common first.
+func (s *FavoritesService) GetGenreStats(userID uint) ([]models.GenreCount, error) {
+	db := database.GetDB()
+	var counts []models.GenreCount
+
+	err := db.Raw(`
+		SELECT g.genre_id::int AS genre_id, COUNT(*) AS count
+		FROM favorite_movies f, jsonb_array_elements_text(f.genre_ids) AS g(genre_id)
+		WHERE f.user_id = ? AND f.deleted_at IS NULL
+		GROUP BY g.genre_id
+		ORDER BY count DESC`, userID).Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range counts {
+		counts[i].Name = s.genreService.GetGenreName(counts[i].GenreID)
+	}
+
+	return counts, nil
+}
+
+// GetDirectorStats returns the directors of the most movies the user has
+// watched, most watched first. Directors are only known for movies the
+// metadata refresh has synced.
+func (s *FavoritesService) GetDirectorStats(userID uint, limit int) ([]models.DirectorCount, error) {
+	db := database.GetDB()
+	var counts []models.DirectorCount
+
+	err := db.Raw(`
+		SELECT d.person_id, d.name, d.profile_path, COUNT(*) AS count
+		FROM favorite_movies f
+		JOIN movie_directors d ON d.tmdb_id = f.tmdb_id
+		WHERE f.user_id = ? AND f.status = ? AND f.deleted_at IS NULL
+		GROUP BY d.person_id, d.name, d.profile_path
+		ORDER BY count DESC, d.name ASC
+		LIMIT ?`, userID, models.StatusWatched, limit).Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// parseReleaseDate parses a TMDB release date, returning nil if it is empty or malformed.
+func parseReleaseDate(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// buildSearchQuery turns free text into a prefix-matching tsquery
+// ("blade run" -> "blade:* & run:*") so live search matches partial words.
+// Punctuation is dropped, which also keeps tsquery syntax out of user input.
+func buildSearchQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, strings.ToLower(word)+":*")
+	}
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"movie-tracker/database"
+	"movie-tracker/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInTrash is returned when adding a movie that is in the user's trash:
+// it has to be restored, keeping its history, rating and notes.
+var ErrInTrash = errors.New("movie is in the trash, restore it from there")
+
+var trashRetention = 30 * 24 * time.Hour
+
+// TrashRetention is how long deleted favorites stay restorable before the
+// purge job removes them for good.
+func TrashRetention() time.Duration {
+	return trashRetention
+}
+
+// SetTrashRetention sets TrashRetention (from TRASH_RETENTION_DAYS). Call it
+// at startup.
+func SetTrashRetention(retention time.Duration) {
+	if retention > 0 {
+		trashRetention = retention
+	}
+}
+
+// GetTrash returns the user's soft-deleted favorites, most recently deleted first.
+func (s *FavoritesService) GetTrash(userID uint) ([]models.FavoriteMovie, error) {
+	db := database.GetDB()
+	var favorites []models.FavoriteMovie
+
+	err := db.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Find(&favorites).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return favorites, nil
+}
+
+// RestoreFavorite moves a favorite out of the trash, recording it in the
+// favorite's history.
+func (s *FavoritesService) RestoreFavorite(id, userID uint, source models.ChangeSource) (*models.FavoriteMovie, error) {
+	db := database.GetDB()
+
+	var favorite models.FavoriteMovie
+	err := db.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		First(&favorite).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("movie not found in trash")
+		}
+		return nil, err
+	}
+
+	// The movie may have been added again since it was deleted
+	var active int64
+	err = db.Model(&models.FavoriteMovie{}).
+		Where("user_id = ? AND tmdb_id = ?", userID, favorite.TMDBId).
+		Count(&active).Error
+	if err != nil {
+		return nil, err
+	}
+	if active > 0 {
+		return nil, errors.New("movie is already in favorites")
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"deleted_at": nil}
+		if err := recordChanges(tx, &favorite, updates, source); err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&favorite).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	favorite.DeletedAt = gorm.DeletedAt{}
+
+	Publish(Event{
+		Type:     EventFavoriteRestored,
+		UserID:   userID,
+		Favorite: favorite,
+		Source:   source,
+	})
+
+	return &favorite, nil
+}
+
+// PurgeFavorite permanently deletes a favorite that is in the trash.
+func (s *FavoritesService) PurgeFavorite(id, userID uint) error {
+	db := database.GetDB()
+
+	count, err := purgeFavorites(db, "id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("movie not found in trash")
+	}
+
+	return nil
+}
+
+// EmptyTrash permanently deletes everything in the user's trash.
+func (s *FavoritesService) EmptyTrash(userID uint) error {
+	db := database.GetDB()
+
+	_, err := purgeFavorites(db, "user_id = ? AND deleted_at IS NOT NULL", userID)
+	return err
+}
+
+// PurgeExpiredTrash permanently deletes favorites that have been in the trash
+// for longer than TrashRetention.
+func (s *FavoritesService) PurgeExpiredTrash() error {
+	db := database.GetDB()
+
+	count, err := purgeFavorites(db, "deleted_at < ?", time.Now().Add(-TrashRetention()))
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		log.Printf("Purged %d favorites from the trash", count)
+	}
+
+	return nil
+}
+
+// purgeFavorites hard-deletes the favorites matching the query, together
+// with their tag and list memberships, their history and the feed
+// activities about them, and returns how many were removed.
+func purgeFavorites(db *gorm.DB, query string, args ...interface{}) (int64, error) {
+	var count int64
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := tx.Unscoped().Model(&models.FavoriteMovie{}).Where(query, args...).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Exec("DELETE FROM favorite_movie_tags WHERE favorite_movie_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM favorite_movie_lists WHERE favorite_movie_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("favorite_movie_id IN ?", ids).Delete(&models.FavoriteHistory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("favorite_movie_id IN ?", ids).Delete(&models.Activity{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(&models.FavoriteMovie{})
+		count = result.RowsAffected
+		return result.Error
+	})
+
+	return count, err
+}
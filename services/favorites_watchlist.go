@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"movie-tracker/database"
+	"movie-tracker/models"
+
+	"gorm.io/gorm"
+)
+
+// Watchlist positions are fractional so that moving a movie only rewrites
+// that movie's row: it takes the midpoint between its new neighbours. When
+// repeated moves leave no room between two neighbours the whole watchlist is
+// renumbered once.
+const minPositionGap = 1e-9
+
+// topPosition returns a position that sorts before every movie on the user's
+// watchlist.
+func topPosition(tx *gorm.DB, userID uint) (float64, error) {
+	var top float64
+	err := tx.Model(&models.FavoriteMovie{}).
+		Select("COALESCE(MIN(position), 0) - 1").
+		Where("user_id = ? AND status = ?", userID, models.StatusToBe).
+		Scan(&top).Error
+	return top, err
+}
+
+// entersWatchlist reports whether updates move favorite back onto the
+// watchlist without placing it, so it needs a position: otherwise it would
+// keep the one it had when it left, or none.
+func entersWatchlist(favorite *models.FavoriteMovie, updates map[string]interface{}) bool {
+	status, ok := updates["status"].(models.Status)
+	if !ok || status != models.StatusToBe || favorite.Status == models.StatusToBe {
+		return false
+	}
+	_, placed := updates["position"]
+	return !placed
+}
+
+// MoveFavorite places a to-watch movie between afterID (the movie that should
+// precede it) and beforeID (the movie that should follow it). Either may be
+// nil when the movie is dropped at the start or end of the list.
+func (s *FavoritesService) MoveFavorite(id, userID uint, afterID, beforeID *uint) (*models.FavoriteMovie, error) {
+	favorite, err := s.GetFavoriteByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if favorite.Status != models.StatusToBe {
+		return nil, errors.New("only movies to watch can be reordered")
+	}
+
+	db := database.GetDB()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		position, ok, err := positionBetween(tx, userID, afterID, beforeID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if err := renumberWatchlist(tx, userID); err != nil {
+				return err
+			}
+			if position, _, err = positionBetween(tx, userID, afterID, beforeID); err != nil {
+				return err
+			}
+		}
+
+		favorite.Position = position
+		return tx.Model(favorite).UpdateColumn("position", position).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return favorite, nil
+}
+
+// MoveToTop puts a to-watch movie first on the watchlist. Movies with another
+// status are rejected rather than moved back, which would lose their
+// watched date.
+func (s *FavoritesService) MoveToTop(id, userID uint, source models.ChangeSource) (*models.FavoriteMovie, error) {
+	favorite, err := s.GetFavoriteByID(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if favorite.Status != models.StatusToBe {
+		return nil, errors.New("only movies to watch can be moved to the top")
+	}
+
+	top, err := topPosition(database.GetDB(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"position": top,
+	}
+	return s.saveUpdates(favorite, updates, source)
+}
+
+// GetFavoriteByTMDBID finds the user's copy of a TMDB movie.
+func (s *FavoritesService) GetFavoriteByTMDBID(userID uint, tmdbID int) (*models.FavoriteMovie, error) {
+	db := database.GetDB()
+	var favorite models.FavoriteMovie
+
+	if err := db.Where("user_id = ? AND tmdb_id = ?", userID, tmdbID).First(&favorite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("favorite movie not found")
+		}
+		return nil, err
+	}
+
+	return &favorite, nil
+}
+
+// UpdatePriority flags a movie with priority.
+func (s *FavoritesService) UpdatePriority(id, userID uint, priority models.Priority, source models.ChangeSource) (*models.FavoriteMovie, error) {
+	if !models.IsValidPriority(priority) {
+		return nil, errors.New("invalid priority")
+	}
+
+	updates := map[string]interface{}{
+		"priority": priority,
+	}
+	return s.UpdateFavorite(id, userID, updates, source)
+}
+
+// positionBetween computes the position halfway between two watchlist
+// neighbours. It reports false when they are too close to split.
+func positionBetween(tx *gorm.DB, userID uint, afterID, beforeID *uint) (float64, bool, error) {
+	after, err := neighbourPosition(tx, userID, afterID)
+	if err != nil {
+		return 0, false, err
+	}
+	before, err := neighbourPosition(tx, userID, beforeID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if after == nil && before == nil {
+		top, err := topPosition(tx, userID)
+		return top, err == nil, err
+	}
+	position, ok := splitPositions(after, before)
+	return position, ok, nil
+}
+
+// splitPositions returns the position between after and before, at least
+// one of them set. It reports false when they are too close to split.
+func splitPositions(after, before *float64) (float64, bool) {
+	switch {
+	case after != nil && before != nil:
+		if *before-*after < minPositionGap {
+			return 0, false
+		}
+		return (*after + *before) / 2, true
+	case after != nil:
+		return *after + 1, true
+	default:
+		return *before - 1, true
+	}
+}
+
+func neighbourPosition(tx *gorm.DB, userID uint, id *uint) (*float64, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	var neighbour models.FavoriteMovie
+	err := tx.Select("position").
+		Where("id = ? AND user_id = ? AND status = ?", *id, userID, models.StatusToBe).
+		First(&neighbour).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("neighbouring movie not found on the watchlist")
+		}
+		return nil, err
+	}
+
+	return &neighbour.Position, nil
+}
+
+// renumberWatchlist spreads the user's watchlist out to whole-number
+// positions, keeping its current order.
+func renumberWatchlist(tx *gorm.DB, userID uint) error {
+	var ids []uint
+	err := tx.Model(&models.FavoriteMovie{}).
+		Where("user_id = ? AND status = ?", userID, models.StatusToBe).
+		Order("position ASC, added_at DESC").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	for id, position := range renumberedPositions(ids) {
+		if err := tx.Model(&models.FavoriteMovie{}).Where("id = ?", id).UpdateColumn("position", position).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// renumberedPositions gives the watchlist movies ids, in order, the whole
+// positions 1, 2, 3...
+func renumberedPositions(ids []uint) map[uint]float64 {
+	positions := make(map[uint]float64, len(ids))
+	for i, id := range ids {
+		positions[id] = float64(i + 1)
+	}
+	return positions
+}
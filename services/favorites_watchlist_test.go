@@ -0,0 +1,124 @@
+package services
+
+import (
+	"movie-tracker/models"
+	"testing"
+)
+
+func float64Ptr(p float64) *float64 {
+	return &p
+}
+
+func TestSplitPositions(t *testing.T) {
+	tests := []struct {
+		name   string
+		after  *float64
+		before *float64
+		want   float64
+		wantOK bool
+	}{
+		{"between neighbours", float64Ptr(1), float64Ptr(2), 1.5, true},
+		{"between negative neighbours", float64Ptr(-3), float64Ptr(-1), -2, true},
+		{"at the end", float64Ptr(4), nil, 5, true},
+		{"at the start", nil, float64Ptr(1), 0, true},
+		{"neighbours too close", float64Ptr(1), float64Ptr(1 + minPositionGap/2), 0, false},
+		{"same position", float64Ptr(2), float64Ptr(2), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := splitPositions(tt.after, tt.before)
+			if ok != tt.wantOK {
+				t.Fatalf("splitPositions() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("splitPositions() = %v, want %v", got, tt.want)
+			}
+			if ok && tt.after != nil && got <= *tt.after {
+				t.Errorf("splitPositions() = %v, not after %v", got, *tt.after)
+			}
+			if ok && tt.before != nil && got >= *tt.before {
+				t.Errorf("splitPositions() = %v, not before %v", got, *tt.before)
+			}
+		})
+	}
+}
+
+// Moving a movie right after the same neighbour again and again halves the
+// gap each time until it can't be split; renumbering makes room again.
+func TestSplitPositionsUntilRenumbered(t *testing.T) {
+	after, before := 1.0, 2.0
+	moves := 0
+	for {
+		position, ok := splitPositions(&after, &before)
+		if !ok {
+			break
+		}
+		before = position
+		moves++
+		if moves > 100 {
+			t.Fatal("gap never ran out")
+		}
+	}
+	if moves < 20 {
+		t.Errorf("gap ran out after %d moves, want at least 20", moves)
+	}
+
+	positions := renumberedPositions([]uint{7, 3})
+	after, before = positions[7], positions[3]
+	if _, ok := splitPositions(&after, &before); !ok {
+		t.Errorf("splitPositions(%v, %v) failed after renumbering", after, before)
+	}
+}
+
+func TestRenumberedPositions(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []uint
+		want map[uint]float64
+	}{
+		{"empty", nil, map[uint]float64{}},
+		{"one movie", []uint{5}, map[uint]float64{5: 1}},
+		{"keeps order", []uint{9, 2, 4}, map[uint]float64{9: 1, 2: 2, 4: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renumberedPositions(tt.ids)
+			if len(got) != len(tt.want) {
+				t.Fatalf("renumberedPositions() = %v, want %v", got, tt.want)
+			}
+			for id, want := range tt.want {
+				if got[id] != want {
+					t.Errorf("renumberedPositions()[%d] = %v, want %v", id, got[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestEntersWatchlist(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  models.Status
+		updates map[string]interface{}
+		want    bool
+	}{
+		{"watched back to watch", models.StatusWatched, map[string]interface{}{"status": models.StatusToBe}, true},
+		{"abandoned back to watch", models.StatusAbandoned, map[string]interface{}{"status": models.StatusToBe, "abandon_reason": ""}, true},
+		{"already to watch", models.StatusToBe, map[string]interface{}{"status": models.StatusToBe}, false},
+		{"leaving the watchlist", models.StatusToBe, map[string]interface{}{"status": models.StatusWatched}, false},
+		{"between other statuses", models.StatusWatching, map[string]interface{}{"status": models.StatusWatched}, false},
+		{"no status change", models.StatusWatched, map[string]interface{}{"rating": 8}, false},
+		{"position already given", models.StatusWatched, map[string]interface{}{"status": models.StatusToBe, "position": 3.0}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			favorite := &models.FavoriteMovie{Status: tt.status}
+			if got := entersWatchlist(favorite, tt.updates); got != tt.want {
+				t.Errorf("entersWatchlist() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"movie-tracker/database"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FeedItem is an activity of a followed user with the movie it is about.
+type FeedItem struct {
+	models.Activity
+	Username    string     `json:"username"`
+	TMDBId      int        `json:"tmdb_id"`
+	Title       string     `json:"title"`
+	PosterPath  string     `json:"poster_path"`
+	ReleaseDate *time.Time `json:"release_date"`
+}
+
+// likeEscaper escapes the LIKE wildcards in user input.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type FollowService struct {
+	notificationService *NotificationService
+}
+
+func NewFollowService() *FollowService {
+	return &FollowService{
+		notificationService: NewNotificationService(),
+	}
+}
+
+// Subscribe starts recording the activity shown in followers' feeds: movies
+// added, marked watched and rated. Imports are left out so they don't flood
+// the feeds.
+func (s *FollowService) Subscribe() {
+	Subscribe(s.handleEvent, EventFavoriteAdded, EventFavoriteStatusChanged, EventFavoriteRated)
+}
+
+// SearchUsers returns up to limit users, other than userID, whose username
+// contains query.
+func (s *FollowService) SearchUsers(query string, userID uint, limit int) ([]models.User, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+
+	db := database.GetDB()
+	var users []models.User
+
+	err := db.Where("username ILIKE ? AND id <> ?", "%"+likeEscaper.Replace(query)+"%", userID).
+		Order("username ASC").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// Follow makes follower follow the user called username. Public profiles
+// are followed right away; private ones get a follow request. Following
+// someone again keeps the existing follow or request.
+func (s *FollowService) Follow(follower *models.User, username string) (*models.Follow, error) {
+	db := database.GetDB()
+
+	var followee models.User
+	if err := db.Where("username = ?", username).First(&followee).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	if followee.ID == follower.ID {
+		return nil, errors.New("you can't follow yourself")
+	}
+
+	follow := &models.Follow{
+		FollowerID: follower.ID,
+		FolloweeID: followee.ID,
+		Status:     models.FollowPending,
+	}
+	if !followee.PrivateProfile {
+		now := time.Now()
+		follow.Status = models.FollowAccepted
+		follow.AcceptedAt = &now
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(follow)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		if err := db.Where("follower_id = ? AND followee_id = ?", follower.ID, followee.ID).First(follow).Error; err != nil {
+			return nil, err
+		}
+		return follow, nil
+	}
+
+	lang := i18n.Resolve(followee.Language, "")
+	notification := models.Notification{
+		Type:  models.NotificationFollow,
+		Key:   fmt.Sprintf("follow:%d", follower.ID),
+		Title: i18n.T(lang, "%s started following you", follower.Username),
+		URL:   "/people",
+	}
+	if follow.Status == models.FollowPending {
+		notification.Key = fmt.Sprintf("follow-request:%d", follower.ID)
+		notification.Title = i18n.T(lang, "%s wants to follow you", follower.Username)
+	}
+	if err := s.notificationService.Notify(&followee, notification); err != nil {
+		log.Printf("Failed to notify user %d of follower %d: %v", followee.ID, follower.ID, err)
+	}
+
+	return follow, nil
+}
+
+// Unfollow stops followerID following followeeID, or cancels the request.
+func (s *FollowService) Unfollow(followerID, followeeID uint) error {
+	db := database.GetDB()
+
+	result := db.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&models.Follow{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Accept accepts the pending follow request of followerID to followee.
+func (s *FollowService) Accept(followee *models.User, followerID uint) error {
+	db := database.GetDB()
+
+	result := db.Model(&models.Follow{}).
+		Where("follower_id = ? AND followee_id = ? AND status = ?", followerID, followee.ID, models.FollowPending).
+		Updates(map[string]interface{}{
+			"status":      models.FollowAccepted,
+			"accepted_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	var follower models.User
+	if err := db.First(&follower, followerID).Error; err != nil {
+		log.Printf("Failed to load follower %d: %v", followerID, err)
+		return nil
+	}
+	err := s.notificationService.Notify(&follower, models.Notification{
+		Type:  models.NotificationFollow,
+		Key:   fmt.Sprintf("follow-accepted:%d", followee.ID),
+		Title: i18n.T(i18n.Resolve(follower.Language, ""), "%s accepted your follow request", followee.Username),
+		URL:   "/people",
+	})
+	if err != nil {
+		log.Printf("Failed to notify user %d of accepted follow: %v", followerID, err)
+	}
+
+	return nil
+}
+
+// RemoveFollower declines the follow request of followerID to followeeID,
+// or stops an accepted follower from following.
+func (s *FollowService) RemoveFollower(followeeID, followerID uint) error {
+	return s.Unfollow(followerID, followeeID)
+}
+
+// AcceptAllRequests accepts every pending follow request to userID, for
+// when the user makes their profile public.
+func (s *FollowService) AcceptAllRequests(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.Follow{}).
+		Where("followee_id = ? AND status = ?", userID, models.FollowPending).
+		Updates(map[string]interface{}{
+			"status":      models.FollowAccepted,
+			"accepted_at": time.Now(),
+		}).Error
+}
+
+// GetFollowing returns who userID follows or asked to follow, with the
+// followed users loaded.
+func (s *FollowService) GetFollowing(userID uint) ([]models.Follow, error) {
+	db := database.GetDB()
+	var follows []models.Follow
+
+	err := db.Preload("Followee").
+		Joins("JOIN users ON users.id = follows.followee_id AND users.deleted_at IS NULL").
+		Where("follows.follower_id = ?", userID).
+		Order("users.username ASC").
+		Find(&follows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return follows, nil
+}
+
+// GetFollowers returns the follows of userID with the given status, with
+// the followers loaded: the followers, or the pending requests.
+func (s *FollowService) GetFollowers(userID uint, status models.FollowStatus) ([]models.Follow, error) {
+	db := database.GetDB()
+	var follows []models.Follow
+
+	err := db.Preload("Follower").
+		Joins("JOIN users ON users.id = follows.follower_id AND users.deleted_at IS NULL").
+		Where("follows.followee_id = ? AND follows.status = ?", userID, status).
+		Order("follows.created_at DESC").
+		Find(&follows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return follows, nil
+}
+
+// FollowStatuses returns, by user ID, the status of every follow of userID,
+// so lists of users can show whether each one is followed.
+func (s *FollowService) FollowStatuses(userID uint) (map[uint]models.FollowStatus, error) {
+	db := database.GetDB()
+	var follows []models.Follow
+
+	if err := db.Where("follower_id = ?", userID).Find(&follows).Error; err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[uint]models.FollowStatus, len(follows))
+	for _, follow := range follows {
+		statuses[follow.FolloweeID] = follow.Status
+	}
+	return statuses, nil
+}
+
+// Connections returns the users who follow userID or are followed by them,
+// ordered by username: the people userID can recommend movies to.
+func (s *FollowService) Connections(userID uint) ([]models.User, error) {
+	db := database.GetDB()
+	var users []models.User
+
+	err := db.Where("id IN (SELECT followee_id FROM follows WHERE follower_id = ? AND status = ?) OR id IN (SELECT follower_id FROM follows WHERE followee_id = ? AND status = ?)",
+		userID, models.FollowAccepted, userID, models.FollowAccepted).
+		Order("username ASC").
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// IsConnected reports whether either user follows the other.
+func (s *FollowService) IsConnected(userID, otherID uint) (bool, error) {
+	db := database.GetDB()
+	var count int64
+
+	err := db.Model(&models.Follow{}).
+		Where("status = ? AND ((follower_id = ? AND followee_id = ?) OR (follower_id = ? AND followee_id = ?))",
+			models.FollowAccepted, userID, otherID, otherID, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetFeed returns the latest activity of the users userID follows, leaving
+// out what each of them chose to keep out of feeds and movies they have
+// since deleted.
+func (s *FollowService) GetFeed(userID uint, limit int) ([]FeedItem, error) {
+	db := database.GetDB()
+	var items []FeedItem
+
+	err := db.Table("activities").
+		Select("activities.*, users.username, favorite_movies.tmdb_id, favorite_movies.title, favorite_movies.poster_path, favorite_movies.release_date").
+		Joins("JOIN follows ON follows.followee_id = activities.user_id AND follows.follower_id = ? AND follows.status = ?", userID, models.FollowAccepted).
+		Joins("JOIN users ON users.id = activities.user_id AND users.deleted_at IS NULL").
+		Joins("JOIN favorite_movies ON favorite_movies.id = activities.favorite_movie_id AND favorite_movies.deleted_at IS NULL").
+		Where("activities.type <> ? OR NOT users.feed_hide_added", models.ActivityAdded).
+		Where("activities.type <> ? OR NOT users.feed_hide_watched", models.ActivityWatched).
+		Where("activities.type <> ? OR NOT users.feed_hide_ratings", models.ActivityRated).
+		Order("activities.created_at DESC, activities.id DESC").
+		Limit(limit).
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// handleEvent records the activity a favorites event stands for.
+func (s *FollowService) handleEvent(event Event) {
+	activity := models.Activity{
+		UserID:          event.UserID,
+		FavoriteMovieID: event.Favorite.ID,
+		CreatedAt:       event.OccurredAt,
+	}
+	switch event.Type {
+	case EventFavoriteAdded:
+		activity.Type = models.ActivityAdded
+	case EventFavoriteStatusChanged:
+		if event.ToStatus != models.StatusWatched {
+			return
+		}
+		activity.Type = models.ActivityWatched
+	case EventFavoriteRated:
+		if event.Favorite.Rating == nil {
+			return
+		}
+		activity.Type = models.ActivityRated
+		activity.Rating = event.Favorite.Rating
+	default:
+		return
+	}
+
+	db := database.GetDB()
+	if err := db.Create(&activity).Error; err != nil {
+		log.Printf("Failed to record %s activity of user %d: %v", activity.Type, event.UserID, err)
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"movie-tracker/database"
+	"movie-tracker/models"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// genreCache holds genre names per language so templates and API responses
+// can resolve IDs without hitting the database on every render.
+var genreCache = struct {
+	sync.RWMutex
+	names map[string]map[int]string
+}{names: make(map[string]map[int]string)}
+
+type GenreService struct {
+	tmdbService *TMDBService
+	languages   []string
+}
+
+func NewGenreService() *GenreService {
+	return &GenreService{
+		tmdbService: NewTMDBService(),
+		languages:   genreLanguages,
+	}
+}
+
+// genreLanguages are the TMDB languages genres are synced in, the first one
+// being used when resolving names for display.
+var genreLanguages = []string{"es-MX", "en-US"}
+
+// SetGenreLanguages sets the languages genres are synced in (from
+// GENRE_LANGUAGES). Call it at startup, before any GenreService is created.
+func SetGenreLanguages(languages []string) {
+	if len(languages) > 0 {
+		genreLanguages = languages
+	}
+}
+
+// SyncGenres refreshes the genres table from TMDB for every configured language.
+func (s *GenreService) SyncGenres() error {
+	db := database.GetDB()
+
+	for _, language := range s.languages {
+		genres, err := s.tmdbService.GetGenres(language)
+		if err != nil {
+			return err
+		}
+		if len(genres) == 0 {
+			continue
+		}
+
+		now := time.Now()
+		rows := make([]models.GenreTranslation, 0, len(genres))
+		for _, genre := range genres {
+			rows = append(rows, models.GenreTranslation{
+				GenreID:   genre.ID,
+				Language:  language,
+				Name:      genre.Name,
+				UpdatedAt: now,
+			})
+		}
+
+		err = db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "genre_id"}, {Name: "language"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "updated_at"}),
+		}).Create(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		genreCache.Lock()
+		delete(genreCache.names, language)
+		genreCache.Unlock()
+	}
+
+	return nil
+}
+
+// Language returns the language genre names are resolved in.
+func (s *GenreService) Language() string {
+	return s.languages[0]
+}
+
+// WithLanguage returns a copy of the service that resolves names in
+// language (e.g. "es-MX") if genres are synced in it.
+func (s *GenreService) WithLanguage(language string) *GenreService {
+	for _, synced := range s.languages {
+		if synced == language {
+			localized := *s
+			localized.languages = append([]string{language}, s.languages...)
+			return &localized
+		}
+	}
+	return s
+}
+
+// GetGenres returns all known genres sorted by name.
+func (s *GenreService) GetGenres() []models.Genre {
+	names := s.names()
+
+	genres := make([]models.Genre, 0, len(names))
+	for id, name := range names {
+		genres = append(genres, models.Genre{ID: id, Name: name})
+	}
+	sort.Slice(genres, func(i, j int) bool {
+		return genres[i].Name < genres[j].Name
+	})
+
+	return genres
+}
+
+// GetGenreName returns the name of a genre, or an empty string if it is unknown.
+func (s *GenreService) GetGenreName(id int) string {
+	return s.names()[id]
+}
+
+// GetGenreNames resolves a list of genre IDs, skipping unknown ones.
+func (s *GenreService) GetGenreNames(ids []int) []string {
+	names := s.names()
+
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := names[id]; ok {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func (s *GenreService) names() map[int]string {
+	language := s.Language()
+
+	genreCache.RLock()
+	names, ok := genreCache.names[language]
+	genreCache.RUnlock()
+	if ok {
+		return names
+	}
+
+	db := database.GetDB()
+	var rows []models.GenreTranslation
+	if err := db.Where("language = ?", language).Find(&rows).Error; err != nil {
+		return map[int]string{}
+	}
+
+	names = make(map[int]string, len(rows))
+	for _, row := range rows {
+		names[row.GenreID] = row.Name
+	}
+
+	// Don't cache an empty result so names show up once the first sync finishes
+	if len(names) > 0 {
+		genreCache.Lock()
+		genreCache.names[language] = names
+		genreCache.Unlock()
+	}
+
+	return names
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"movie-tracker/database"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"time"
+)
+
+type GoalsService struct {
+	genreService *GenreService
+}
+
+func NewGoalsService() *GoalsService {
+	return &GoalsService{
+		genreService: NewGenreService(),
+	}
+}
+
+func (s *GoalsService) CreateGoal(goal *models.Goal) error {
+	if err := s.validateGoal(goal); err != nil {
+		return err
+	}
+
+	db := database.GetDB()
+	return db.Create(goal).Error
+}
+
+func (s *GoalsService) GetUserGoals(userID uint) ([]models.Goal, error) {
+	db := database.GetDB()
+	var goals []models.Goal
+
+	if err := db.Where("user_id = ?", userID).Order("created_at ASC").Find(&goals).Error; err != nil {
+		return nil, err
+	}
+
+	return goals, nil
+}
+
+func (s *GoalsService) DeleteGoal(id, userID uint) error {
+	db := database.GetDB()
+
+	result := db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Goal{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("goal not found")
+	}
+
+	return nil
+}
+
+// GetGoalProgress evaluates every goal of the user against their watched
+// movies, describing the pace and naming genres in lang.
+func (s *GoalsService) GetGoalProgress(userID uint, lang string) ([]models.GoalProgress, error) {
+	goals, err := s.GetUserGoals(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	genres := s.genreService.WithLanguage(i18n.TMDBLocale(lang))
+	now := time.Now()
+	progress := make([]models.GoalProgress, 0, len(goals))
+	for _, goal := range goals {
+		completed, err := s.countCompleted(&goal)
+		if err != nil {
+			return nil, err
+		}
+		goalProgress := evaluateGoal(goal, completed, now, lang)
+		if goal.GenreID != nil {
+			goalProgress.GenreName = genres.GetGenreName(*goal.GenreID)
+		}
+		progress = append(progress, goalProgress)
+	}
+
+	return progress, nil
+}
+
+func (s *GoalsService) countCompleted(goal *models.Goal) (int, error) {
+	db := database.GetDB()
+
+	query := db.Model(&models.FavoriteMovie{}).
+		Where("user_id = ? AND status = ?", goal.UserID, models.StatusWatched)
+
+	if goal.StartsAt != nil {
+		query = query.Where("watched_at >= ?", *goal.StartsAt)
+	}
+	if goal.EndsAt != nil {
+		// EndsAt is inclusive, so count everything before the following day
+		query = query.Where("watched_at < ?", goal.EndsAt.AddDate(0, 0, 1))
+	}
+	if goal.GenreID != nil {
+		query = query.Where("genre_ids @> ?::jsonb", fmt.Sprintf("[%d]", *goal.GenreID))
+	}
+	if goal.ReleaseYearFrom != nil {
+		query = query.Where("release_date >= ?", time.Date(*goal.ReleaseYearFrom, time.January, 1, 0, 0, 0, 0, time.UTC))
+	}
+	if goal.ReleaseYearTo != nil {
+		query = query.Where("release_date < ?", time.Date(*goal.ReleaseYearTo+1, time.January, 1, 0, 0, 0, 0, time.UTC))
+	}
+	if goal.OriginalLanguage != "" {
+		query = query.Where("original_language = ?", goal.OriginalLanguage)
+	}
+	if goal.MinRuntime != nil {
+		query = query.Where("runtime >= ?", *goal.MinRuntime)
+	}
+	if goal.MaxRuntime != nil {
+		query = query.Where("runtime <= ?", *goal.MaxRuntime)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (s *GoalsService) validateGoal(goal *models.Goal) error {
+	if goal.Title == "" {
+		return errors.New("goal title is required")
+	}
+
+	if goal.Target <= 0 {
+		return errors.New("goal target must be greater than zero")
+	}
+
+	if goal.StartsAt != nil && goal.EndsAt != nil && goal.EndsAt.Before(*goal.StartsAt) {
+		return errors.New("goal end date must be after its start date")
+	}
+
+	if goal.ReleaseYearFrom != nil && goal.ReleaseYearTo != nil && *goal.ReleaseYearTo < *goal.ReleaseYearFrom {
+		return errors.New("release year range is invalid")
+	}
+
+	if goal.MinRuntime != nil && goal.MaxRuntime != nil && *goal.MaxRuntime < *goal.MinRuntime {
+		return errors.New("runtime range is invalid")
+	}
+
+	return nil
+}
+
+// evaluateGoal computes completion and, for goals with a start and end date,
+// how far ahead or behind a linear schedule the user currently is. The pace
+// is described in lang.
+func evaluateGoal(goal models.Goal, completed int, now time.Time, lang string) models.GoalProgress {
+	progress := models.GoalProgress{
+		Goal:      goal,
+		Completed: completed,
+		Percent:   int(math.Min(100, float64(completed)*100/float64(goal.Target))),
+		Done:      completed >= goal.Target,
+	}
+
+	switch {
+	case progress.Done:
+		progress.Pace = i18n.T(lang, "Goal reached 🎉")
+	case goal.StartsAt == nil || goal.EndsAt == nil:
+		progress.Pace = i18n.T(lang, "%s to go", pluralizeFilms(lang, goal.Target-completed))
+	case now.Before(*goal.StartsAt):
+		progress.Pace = i18n.T(lang, "Starts %s", i18n.FormatDate(lang, *goal.StartsAt))
+	case !now.Before(goal.EndsAt.AddDate(0, 0, 1)):
+		progress.Pace = i18n.T(lang, "Ended %s short", pluralizeFilms(lang, goal.Target-completed))
+	default:
+		end := goal.EndsAt.AddDate(0, 0, 1)
+		elapsed := now.Sub(*goal.StartsAt).Seconds() / end.Sub(*goal.StartsAt).Seconds()
+		expected := int(math.Floor(float64(goal.Target) * elapsed))
+		progress.Expected = &expected
+		progress.Ahead = completed - expected
+
+		switch {
+		case progress.Ahead > 0:
+			progress.Pace = i18n.T(lang, "%s ahead of schedule", pluralizeFilms(lang, progress.Ahead))
+		case progress.Ahead < 0:
+			progress.Pace = i18n.T(lang, "%s behind schedule", pluralizeFilms(lang, -progress.Ahead))
+		default:
+			progress.Pace = i18n.T(lang, "On schedule")
+		}
+	}
+
+	return progress
+}
+
+func pluralizeFilms(lang string, n int) string {
+	if n == 1 {
+		return i18n.T(lang, "1 film")
+	}
+	return i18n.T(lang, "%d films", n)
+}
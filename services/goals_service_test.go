@@ -0,0 +1,95 @@
+package services
+
+import (
+	"movie-tracker/models"
+	"testing"
+	"time"
+)
+
+func TestEvaluateGoal(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, time.January, 10, 0, 0, 0, 0, time.UTC) // Counts until the end of the day
+	dated := models.Goal{Target: 10, StartsAt: &start, EndsAt: &end}
+	undated := models.Goal{Target: 10}
+
+	intPtr := func(n int) *int { return &n }
+
+	tests := []struct {
+		name      string
+		goal      models.Goal
+		completed int
+		now       time.Time
+		lang      string
+		pace      string
+		percent   int
+		done      bool
+		expected  *int
+		ahead     int
+	}{
+		{
+			name: "no dates", goal: undated, completed: 3, now: start, lang: "en",
+			pace: "7 films to go", percent: 30,
+		},
+		{
+			name: "no dates, one left", goal: undated, completed: 9, now: start, lang: "en",
+			pace: "1 film to go", percent: 90,
+		},
+		{
+			name: "reached", goal: dated, completed: 10, now: start.AddDate(0, 0, 3), lang: "en",
+			pace: "Goal reached 🎉", percent: 100, done: true,
+		},
+		{
+			name: "past the target", goal: undated, completed: 14, now: start, lang: "en",
+			pace: "Goal reached 🎉", percent: 100, done: true,
+		},
+		{
+			name: "before the start", goal: dated, completed: 0, now: start.Add(-time.Hour), lang: "en",
+			pace: "Starts Jan 1, 2026",
+		},
+		{
+			name: "before the start, in Spanish", goal: dated, completed: 0, now: start.Add(-time.Hour), lang: "es",
+			pace: "Empieza el 01/01/2026",
+		},
+		{
+			name: "right at the start", goal: dated, completed: 0, now: start, lang: "en",
+			pace: "On schedule", expected: intPtr(0),
+		},
+		{
+			name: "ahead", goal: dated, completed: 7, now: start.AddDate(0, 0, 5), lang: "en",
+			pace: "2 films ahead of schedule", percent: 70, expected: intPtr(5), ahead: 2,
+		},
+		{
+			name: "behind", goal: dated, completed: 4, now: start.AddDate(0, 0, 5), lang: "en",
+			pace: "1 film behind schedule", percent: 40, expected: intPtr(5), ahead: -1,
+		},
+		{
+			name: "last day", goal: dated, completed: 9, now: end.Add(23 * time.Hour), lang: "en",
+			pace: "On schedule", percent: 90, expected: intPtr(9),
+		},
+		{
+			name: "ended", goal: dated, completed: 7, now: end.AddDate(0, 0, 1), lang: "en",
+			pace: "Ended 3 films short", percent: 70,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := evaluateGoal(tt.goal, tt.completed, tt.now, tt.lang)
+			if got.Pace != tt.pace {
+				t.Errorf("Pace = %q, want %q", got.Pace, tt.pace)
+			}
+			if got.Percent != tt.percent {
+				t.Errorf("Percent = %d, want %d", got.Percent, tt.percent)
+			}
+			if got.Done != tt.done {
+				t.Errorf("Done = %v, want %v", got.Done, tt.done)
+			}
+			if (got.Expected == nil) != (tt.expected == nil) || (got.Expected != nil && *got.Expected != *tt.expected) {
+				t.Errorf("Expected = %v, want %v", got.Expected, tt.expected)
+			}
+			if got.Ahead != tt.ahead {
+				t.Errorf("Ahead = %d, want %d", got.Ahead, tt.ahead)
+			}
+		})
+	}
+}
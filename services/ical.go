@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"movie-tracker/i18n"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icalLineLength is the longest a content line may be, in bytes, before it
+// has to be folded (RFC 5545 section 3.1).
+const icalLineLength = 75
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// WriteICS writes entries as an iCalendar feed named name, with one all-day
+// event per release. Release types are labelled in lang, and each event
+// links to the movie's page under baseURL. UIDs only depend on the movie,
+// region, type and date, so calendar apps update their copies on refresh.
+func WriteICS(w io.Writer, name, lang, baseURL string, entries []CalendarEntry) error {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		writeICSLine(&b, fmt.Sprintf(format, args...))
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Movie Tracker//Release Calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:%s", icalEscaper.Replace(name))
+	for _, entry := range entries {
+		region := entry.Region
+		if region == "" {
+			region = "all"
+		}
+		date := entry.Date.UTC()
+
+		line("BEGIN:VEVENT")
+		line("UID:%d-%s-%d-%s@movie-tracker", entry.TMDBId, region, entry.Type, date.Format("20060102"))
+		line("DTSTAMP:%s", stamp)
+		line("DTSTART;VALUE=DATE:%s", date.Format("20060102"))
+		line("DTEND;VALUE=DATE:%s", date.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:%s", icalEscaper.Replace(fmt.Sprintf("%s %s (%s)", entry.Type.Icon(), entry.Title, i18n.T(lang, entry.Type.Label()))))
+		if entry.Note != "" {
+			line("DESCRIPTION:%s", icalEscaper.Replace(entry.Note))
+		}
+		line("URL:%s/movie/%d", baseURL, entry.TMDBId)
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeICSLine writes a content line, folding it into continuation lines
+// that start with a space when it is too long. Lines are only split between
+// UTF-8 characters.
+func writeICSLine(b *strings.Builder, content string) {
+	limit := icalLineLength
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		// The leading space counts towards the next line's length
+		limit = icalLineLength - 1
+	}
+	b.WriteString(content)
+	b.WriteString("\r\n")
+}
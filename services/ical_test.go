@@ -0,0 +1,105 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWriteICSLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // Physical lines, without CRLF
+	}{
+		{
+			name:    "short line",
+			content: "SUMMARY:Dune",
+			want:    []string{"SUMMARY:Dune"},
+		},
+		{
+			name:    "empty line",
+			content: "",
+			want:    []string{""},
+		},
+		{
+			name:    "exactly the limit",
+			content: strings.Repeat("a", 75),
+			want:    []string{strings.Repeat("a", 75)},
+		},
+		{
+			name:    "one byte over",
+			content: strings.Repeat("a", 76),
+			want:    []string{strings.Repeat("a", 75), " a"},
+		},
+		{
+			name:    "several continuation lines",
+			content: strings.Repeat("a", 75+74+10),
+			want:    []string{strings.Repeat("a", 75), " " + strings.Repeat("a", 74), " " + strings.Repeat("a", 10)},
+		},
+		{
+			name:    "two-byte character across the limit",
+			content: strings.Repeat("a", 74) + "ñb",
+			want:    []string{strings.Repeat("a", 74), " ñb"},
+		},
+		{
+			name:    "four-byte character across the limit",
+			content: strings.Repeat("a", 73) + "🎬b",
+			want:    []string{strings.Repeat("a", 73), " 🎬b"},
+		},
+		{
+			name:    "character ending right at the limit",
+			content: strings.Repeat("a", 73) + "ñb",
+			want:    []string{strings.Repeat("a", 73) + "ñ", " b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeICSLine(&b, tt.content)
+
+			want := strings.Join(tt.want, "\r\n") + "\r\n"
+			if got := b.String(); got != want {
+				t.Errorf("writeICSLine() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// Folded lines stay within the limit, never split a character and unfold
+// back to the original content.
+func TestWriteICSLineFolding(t *testing.T) {
+	contents := []string{
+		strings.Repeat("é", 200),
+		strings.Repeat("映画", 100),
+		strings.Repeat("🍿x", 60),
+		"DESCRIPTION:" + strings.Repeat("Película en versión original subtitulada\\, ", 8),
+	}
+
+	for _, content := range contents {
+		var b strings.Builder
+		writeICSLine(&b, content)
+
+		output := b.String()
+		if !strings.HasSuffix(output, "\r\n") {
+			t.Fatalf("writeICSLine(%q) = %q, doesn't end with CRLF", content, output)
+		}
+		for i, line := range strings.Split(strings.TrimSuffix(output, "\r\n"), "\r\n") {
+			if len(line) > icalLineLength {
+				t.Errorf("line %d is %d bytes long, want at most %d", i, len(line), icalLineLength)
+			}
+			if !utf8.ValidString(line) {
+				t.Errorf("line %d splits a character: %q", i, line)
+			}
+			if i > 0 && !strings.HasPrefix(line, " ") {
+				t.Errorf("continuation line %d doesn't start with a space: %q", i, line)
+			}
+		}
+
+		unfolded := strings.ReplaceAll(strings.TrimSuffix(output, "\r\n"), "\r\n ", "")
+		if unfolded != content {
+			t.Errorf("unfolded output = %q, want %q", unfolded, content)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Mailer sends plain-text emails.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer returns an SMTPMailer when SMTP_HOST is set, and a LogMailer
+// otherwise, so development setups don't need a mail server.
+func NewMailer() Mailer {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return LogMailer{}
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "movie-tracker@localhost"
+	}
+
+	return &SMTPMailer{
+		addr:     host + ":" + port,
+		host:     host,
+		username: os.Getenv("SMTP_USERNAME"),
+		password: os.Getenv("SMTP_PASSWORD"),
+		from:     from,
+	}
+}
+
+// LogMailer writes emails to the log instead of sending them.
+type LogMailer struct{}
+
+func (LogMailer) Send(to, subject, body string) error {
+	log.Printf("Email to %s: %s\n%s", to, subject, body)
+	return nil
+}
+
+// SMTPMailer sends emails through an SMTP server, authenticating with PLAIN
+// auth when a username is configured.
+type SMTPMailer struct {
+	addr     string
+	host     string
+	username string
+	password string
+	from     string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	headers := []string{
+		"From: " + m.from,
+		"To: " + to,
+		"Subject: " + mime.QEncoding.Encode("utf-8", subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: 8bit",
+	}
+	message := strings.Join(headers, "\r\n") + "\r\n\r\n" + strings.ReplaceAll(body, "\n", "\r\n")
+
+	if err := smtp.SendMail(m.addr, auth, m.from, []string{to}, []byte(message)); err != nil {
+		return fmt.Errorf("sending email to %s: %w", to, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"log"
+	"movie-tracker/database"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MetadataService keeps the TMDB snapshot stored on each FavoriteMovie
+// (title, poster, genres, runtime...) up to date.
+type MetadataService struct {
+	tmdbService *TMDBService
+}
+
+func NewMetadataService() *MetadataService {
+	return &MetadataService{
+		tmdbService: NewTMDBService(),
+	}
+}
+
+// RefreshStale refreshes up to batchSize movies whose snapshot has never been
+// synced or is older than maxAge, least recently tried first. Each movie is
+// fetched once no matter how many users track it, waiting delay between TMDB
+// requests to stay under the API rate limit. Every try is recorded, so
+// movies TMDB keeps failing on don't hold up the rest of the queue.
+func (s *MetadataService) RefreshStale(batchSize int, maxAge, delay time.Duration) error {
+	db := database.GetDB()
+
+	var tmdbIDs []int
+	err := db.Model(&models.FavoriteMovie{}).
+		Where("metadata_synced_at IS NULL OR metadata_synced_at < ?", time.Now().Add(-maxAge)).
+		Group("tmdb_id").
+		Order("MIN(metadata_tried_at) NULLS FIRST, MIN(metadata_synced_at) NULLS FIRST").
+		Limit(batchSize).
+		Pluck("tmdb_id", &tmdbIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for i, tmdbID := range tmdbIDs {
+		if i > 0 {
+			time.Sleep(delay)
+		}
+		err := db.Model(&models.FavoriteMovie{}).
+			Where("tmdb_id = ?", tmdbID).
+			UpdateColumn("metadata_tried_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		if err := s.RefreshMovie(tmdbID); err != nil {
+			log.Printf("Failed to refresh metadata for TMDB movie %d: %v", tmdbID, err)
+		}
+	}
+
+	return nil
+}
+
+// RefreshMovie updates the snapshot of every FavoriteMovie row for tmdbID
+// and the movie's directors. Titles and overviews are stored in each owner's
+// language, next to the original title.
+func (s *MetadataService) RefreshMovie(tmdbID int) error {
+	db := database.GetDB()
+
+	details, err := s.tmdbService.GetMovieWithTranslations(tmdbID)
+	if err != nil {
+		return err
+	}
+
+	genreIDs := make(models.IntArray, 0, len(details.Genres))
+	for _, genre := range details.Genres {
+		genreIDs = append(genreIDs, genre.ID)
+	}
+
+	var runtime *int
+	if details.Runtime > 0 {
+		runtime = &details.Runtime
+	}
+
+	var directors []models.MovieDirector
+	for _, director := range details.Directors() {
+		directors = append(directors, models.MovieDirector{
+			TMDBId:      tmdbID,
+			PersonID:    director.ID,
+			Name:        director.Name,
+			ProfilePath: director.ProfilePath,
+		})
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tmdb_id = ?", tmdbID).Delete(&models.MovieDirector{}).Error; err != nil {
+			return err
+		}
+		if len(directors) > 0 {
+			if err := tx.Create(&directors).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, language := range i18n.Supported {
+			title, overview := details.Localized(language.TMDB)
+			err := tx.Model(&models.FavoriteMovie{}).
+				Where("tmdb_id = ?", tmdbID).
+				Where("user_id IN (SELECT id FROM users WHERE COALESCE(NULLIF(language, ''), ?) = ?)", i18n.Default, language.Code).
+				UpdateColumns(map[string]interface{}{
+					"title":    title,
+					"overview": overview,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// UpdateColumns skips hooks and updated_at: a metadata sync is not a user edit
+		return tx.Model(&models.FavoriteMovie{}).
+			Where("tmdb_id = ?", tmdbID).
+			UpdateColumns(map[string]interface{}{
+				"original_title":     details.OriginalTitle,
+				"release_date":       parseReleaseDate(details.ReleaseDate),
+				"poster_path":        details.PosterPath,
+				"backdrop_path":      details.BackdropPath,
+				"genre_ids":          genreIDs,
+				"runtime":            runtime,
+				"original_language":  details.OriginalLanguage,
+				"imdb_id":            details.IMDBId,
+				"vote_average":       details.VoteAverage,
+				"metadata_synced_at": time.Now(),
+			}).Error
+	})
+}
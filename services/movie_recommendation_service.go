@@ -0,0 +1,265 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"movie-tracker/database"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// maxRecommendationMessage is the longest message a recommendation can carry,
+// in characters.
+const maxRecommendationMessage = 500
+
+// MovieRecommendationService handles movies users recommend to each other.
+// Users can recommend movies to the people they follow or are followed by.
+type MovieRecommendationService struct {
+	tmdbService         *TMDBService
+	favoritesService    *FavoritesService
+	followService       *FollowService
+	notificationService *NotificationService
+}
+
+func NewMovieRecommendationService() *MovieRecommendationService {
+	return &MovieRecommendationService{
+		tmdbService:         NewTMDBService(),
+		favoritesService:    NewFavoritesService(),
+		followService:       NewFollowService(),
+		notificationService: NewNotificationService(),
+	}
+}
+
+// WithLocale returns a copy of the service that fetches movies from TMDB in
+// language and region, as TMDBService.WithLocale.
+func (s *MovieRecommendationService) WithLocale(language, region string) *MovieRecommendationService {
+	localized := *s
+	localized.tmdbService = s.tmdbService.WithLocale(language, region)
+	return &localized
+}
+
+// Recommend recommends the TMDB movie tmdbID to recipientID with message,
+// and notifies the recipient.
+func (s *MovieRecommendationService) Recommend(sender *models.User, recipientID uint, tmdbID int, message string) (*models.MovieRecommendation, error) {
+	message = strings.TrimSpace(message)
+	if utf8.RuneCountInString(message) > maxRecommendationMessage {
+		return nil, fmt.Errorf("message is longer than %d characters", maxRecommendationMessage)
+	}
+	if recipientID == sender.ID {
+		return nil, errors.New("you can't recommend a movie to yourself")
+	}
+
+	connected, err := s.followService.IsConnected(sender.ID, recipientID)
+	if err != nil {
+		return nil, err
+	}
+	if !connected {
+		return nil, errors.New("you can only recommend movies to people you follow or who follow you")
+	}
+
+	db := database.GetDB()
+	var recipient models.User
+	if err := db.First(&recipient, recipientID).Error; err != nil {
+		return nil, err
+	}
+
+	var pending int64
+	err = db.Model(&models.MovieRecommendation{}).
+		Where("sender_id = ? AND recipient_id = ? AND tmdb_id = ? AND status = ?", sender.ID, recipientID, tmdbID, models.RecommendationPending).
+		Count(&pending).Error
+	if err != nil {
+		return nil, err
+	}
+	if pending > 0 {
+		return nil, fmt.Errorf("you already recommended this movie to %s", recipient.Username)
+	}
+
+	movie, err := s.tmdbService.GetMovieDetails(tmdbID)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendation := &models.MovieRecommendation{
+		SenderID:    sender.ID,
+		RecipientID: recipientID,
+		TMDBId:      tmdbID,
+		Title:       movie.Title,
+		PosterPath:  movie.PosterPath,
+		Message:     message,
+		Status:      models.RecommendationPending,
+	}
+	if err := db.Create(recommendation).Error; err != nil {
+		return nil, err
+	}
+
+	err = s.notificationService.Notify(&recipient, models.Notification{
+		Type:  models.NotificationRecommendation,
+		Key:   fmt.Sprintf("recommendation:%d", recommendation.ID),
+		Title: i18n.T(i18n.Resolve(recipient.Language, ""), "%s recommends you %s", sender.Username, movie.Title),
+		Body:  message,
+		URL:   "/recommended",
+	})
+	if err != nil {
+		log.Printf("Failed to notify user %d of recommendation %d: %v", recipientID, recommendation.ID, err)
+	}
+
+	return recommendation, nil
+}
+
+// GetReceived returns the recommendations made to userID, pending ones
+// first and then newest first, with their senders loaded.
+func (s *MovieRecommendationService) GetReceived(userID uint) ([]models.MovieRecommendation, error) {
+	db := database.GetDB()
+	var recommendations []models.MovieRecommendation
+
+	err := db.Preload("Sender").
+		Where("recipient_id = ?", userID).
+		Order("CASE WHEN status = 'pending' THEN 0 ELSE 1 END, created_at DESC").
+		Find(&recommendations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return recommendations, nil
+}
+
+// GetSent returns the recommendations userID made, newest first, with their
+// recipients and, for accepted ones still in the recipient's favorites, the
+// favorite with its status and rating.
+func (s *MovieRecommendationService) GetSent(userID uint) ([]models.MovieRecommendation, error) {
+	db := database.GetDB()
+	var recommendations []models.MovieRecommendation
+
+	err := db.Preload("Recipient").
+		Preload("FavoriteMovie").
+		Where("sender_id = ?", userID).
+		Order("created_at DESC").
+		Find(&recommendations).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return recommendations, nil
+}
+
+// PendingCount returns how many recommendations wait for userID's answer.
+func (s *MovieRecommendationService) PendingCount(userID uint) (int64, error) {
+	db := database.GetDB()
+	var count int64
+
+	err := db.Model(&models.MovieRecommendation{}).
+		Where("recipient_id = ? AND status = ?", userID, models.RecommendationPending).
+		Count(&count).Error
+	return count, err
+}
+
+// Accept adds the movie of a pending recommendation to the recipient's
+// favorites as recommended, with RecommendedBy naming and linking the
+// sender. A movie the recipient already tracks is linked to the sender
+// unless it already names someone.
+func (s *MovieRecommendationService) Accept(id, recipientID uint, source models.ChangeSource) (*models.MovieRecommendation, error) {
+	recommendation, err := s.getPending(id, recipientID)
+	if err != nil {
+		return nil, err
+	}
+	sender := recommendation.Sender
+
+	db := database.GetDB()
+	var favorite models.FavoriteMovie
+	// Only fetched when the movie has to be added, before the transaction so
+	// that it isn't held open during the TMDB call
+	var movie *models.TMDBMovie
+	err = db.Where("user_id = ? AND tmdb_id = ?", recipientID, recommendation.TMDBId).First(&favorite).Error
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		movie, err = s.tmdbService.GetMovieDetails(recommendation.TMDBId)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	// The favorite and the recommendation are saved together, so a failure
+	// leaves the recommendation pending with nothing half done
+	var events []Event
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if movie != nil {
+			added, event, err := s.favoritesService.createFavorite(tx, recipientID, movie, models.StatusRecommended, nil, "", sender.Username, source)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(added).Update("recommender_id", sender.ID).Error; err != nil {
+				return err
+			}
+			favorite = *added
+			event.Favorite = favorite
+			events = append(events, event)
+		} else if favorite.RecommendedBy == "" {
+			updates := map[string]interface{}{
+				"recommended_by": sender.Username,
+				"recommender_id": sender.ID,
+			}
+			var err error
+			if events, err = s.favoritesService.applyUpdates(tx, &favorite, updates, source); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(recommendation).Updates(map[string]interface{}{
+			"status":            models.RecommendationAccepted,
+			"favorite_movie_id": favorite.ID,
+			"responded_at":      time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	Publish(events...)
+
+	return recommendation, nil
+}
+
+// Decline turns down a pending recommendation.
+func (s *MovieRecommendationService) Decline(id, recipientID uint) (*models.MovieRecommendation, error) {
+	recommendation, err := s.getPending(id, recipientID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB()
+	err = db.Model(recommendation).Updates(map[string]interface{}{
+		"status":       models.RecommendationDeclined,
+		"responded_at": time.Now(),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return recommendation, nil
+}
+
+func (s *MovieRecommendationService) getPending(id, recipientID uint) (*models.MovieRecommendation, error) {
+	db := database.GetDB()
+	var recommendation models.MovieRecommendation
+
+	err := db.Preload("Sender").
+		Where("id = ? AND recipient_id = ? AND status = ?", id, recipientID, models.RecommendationPending).
+		First(&recommendation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("recommendation not found")
+		}
+		return nil, err
+	}
+
+	return &recommendation, nil
+}
@@ -0,0 +1,447 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"movie-tracker/database"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// digestPeriod is how often the weekly digest is sent.
+const digestPeriod = 7 * 24 * time.Hour
+
+// notificationQueue sends notifications by email and webhook in the
+// background, so notifying doesn't wait on mail servers and receivers.
+var notificationQueue = newWorkQueue("notifications", 4, 1000)
+
+// AppURL returns the absolute URL of the app, for links sent outside of it.
+func AppURL() string {
+	if value := os.Getenv("APP_URL"); value != "" {
+		return strings.TrimSuffix(value, "/")
+	}
+	return "http://localhost:8080"
+}
+
+// NotificationSender delivers a stored notification to user on one channel.
+type NotificationSender interface {
+	Send(user *models.User, notification *models.Notification) error
+}
+
+// emailSender emails notifications through a Mailer.
+type emailSender struct {
+	mailer Mailer
+}
+
+func (s emailSender) Send(user *models.User, notification *models.Notification) error {
+	body := notification.Body
+	if notification.URL != "" {
+		body = strings.TrimSpace(body + "\n\n" + AppURL() + notification.URL)
+	}
+	return s.mailer.Send(user.Email, notification.Title, body)
+}
+
+// webhookSender POSTs notifications as JSON to the user's notification
+// webhook, which may only be a public address.
+type webhookSender struct {
+	client *http.Client
+}
+
+func (s webhookSender) Send(user *models.User, notification *models.Notification) error {
+	if user.NotificationWebhook == "" {
+		return nil
+	}
+
+	link := ""
+	if notification.URL != "" {
+		link = AppURL() + notification.URL
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":       notification.Type,
+		"title":      notification.Title,
+		"body":       notification.Body,
+		"url":        link,
+		"created_at": notification.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(user.NotificationWebhook, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NotificationSettings are the notification preferences a user can change.
+type NotificationSettings struct {
+	// Channels says, per type, which channels are enabled. Missing entries
+	// are disabled.
+	Channels     map[models.NotificationType]map[models.NotificationChannel]bool
+	Webhook      string
+	WeeklyDigest bool
+}
+
+// NotificationService stores notifications and delivers them on the
+// channels each user enabled for their type. In-app delivery is the stored
+// notification itself; other channels have a NotificationSender.
+type NotificationService struct {
+	mailer          Mailer
+	senders         map[models.NotificationChannel]NotificationSender
+	calendarService *CalendarService
+}
+
+func NewNotificationService() *NotificationService {
+	mailer := NewMailer()
+	return &NotificationService{
+		mailer: mailer,
+		senders: map[models.NotificationChannel]NotificationSender{
+			models.ChannelEmail:   emailSender{mailer: mailer},
+			models.ChannelWebhook: webhookSender{client: newOutboundClient()},
+		},
+		calendarService: NewCalendarService(),
+	}
+}
+
+// Notify stores notification for user and queues its delivery on the other
+// channels they enabled for its type. A notification whose Key was already
+// used for the user is dropped, so callers can safely notify the same thing
+// again. Delivery failures are logged: the notification stays in the inbox
+// and the digest.
+func (s *NotificationService) Notify(user *models.User, notification models.Notification) error {
+	preferences, err := s.GetPreferences(user.ID)
+	if err != nil {
+		return err
+	}
+	channels := preferences[notification.Type]
+
+	enabled := false
+	for _, on := range channels {
+		enabled = enabled || on
+	}
+	if !enabled {
+		return nil
+	}
+
+	db := database.GetDB()
+
+	notification.ID = 0
+	notification.UserID = user.ID
+	notification.InApp = channels[models.ChannelInApp]
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&notification)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	recipient := *user
+	for channel, sender := range s.senders {
+		if !channels[channel] {
+			continue
+		}
+		notificationQueue.enqueue(func() {
+			if err := sender.Send(&recipient, &notification); err != nil {
+				log.Printf("Failed to send notification %d to user %d by %s: %v", notification.ID, recipient.ID, channel, err)
+			}
+		})
+	}
+
+	return nil
+}
+
+// GetPreferences returns, per notification type, whether each channel is
+// enabled for the user: their saved choice, or the type's default.
+func (s *NotificationService) GetPreferences(userID uint) (map[models.NotificationType]map[models.NotificationChannel]bool, error) {
+	preferences := make(map[models.NotificationType]map[models.NotificationChannel]bool)
+	for _, def := range models.NotificationTypes {
+		preferences[def.Type] = make(map[models.NotificationChannel]bool)
+		for _, channel := range def.DefaultChannels {
+			preferences[def.Type][channel] = true
+		}
+	}
+
+	db := database.GetDB()
+	var rows []models.NotificationPreference
+	if err := db.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if channels, ok := preferences[row.Type]; ok {
+			channels[row.Channel] = row.Enabled
+		}
+	}
+
+	return preferences, nil
+}
+
+// UpdatePreferences saves the user's choice for every notification type and
+// channel, their webhook URL and whether they get the weekly digest.
+func (s *NotificationService) UpdatePreferences(user *models.User, settings NotificationSettings) error {
+	if settings.Webhook != "" {
+		if err := validateOutboundURL(settings.Webhook); err != nil {
+			return err
+		}
+	}
+
+	rows := make([]models.NotificationPreference, 0, len(models.NotificationTypes)*len(models.NotificationChannels))
+	for _, def := range models.NotificationTypes {
+		for _, channel := range models.NotificationChannels {
+			rows = append(rows, models.NotificationPreference{
+				UserID:  user.ID,
+				Type:    def.Type,
+				Channel: channel.Channel,
+				Enabled: settings.Channels[def.Type][channel.Channel],
+			})
+		}
+	}
+
+	db := database.GetDB()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "channel"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled"}),
+		}).Create(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(user).Updates(map[string]interface{}{
+			"notification_webhook": settings.Webhook,
+			"weekly_digest":        settings.WeeklyDigest,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	user.NotificationWebhook = settings.Webhook
+	user.WeeklyDigest = settings.WeeklyDigest
+	return nil
+}
+
+// GetInbox returns the user's in-app notifications, newest first.
+func (s *NotificationService) GetInbox(userID uint, limit int) ([]models.Notification, error) {
+	db := database.GetDB()
+
+	var notifications []models.Notification
+	err := db.Where("user_id = ? AND in_app", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// UnreadCount returns how many in-app notifications the user hasn't read.
+func (s *NotificationService) UnreadCount(userID uint) (int64, error) {
+	db := database.GetDB()
+
+	var count int64
+	err := db.Model(&models.Notification{}).
+		Where("user_id = ? AND in_app AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks one of the user's notifications read and returns it.
+func (s *NotificationService) MarkRead(id, userID uint) (*models.Notification, error) {
+	db := database.GetDB()
+
+	var notification models.Notification
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		return nil, err
+	}
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := db.Model(&notification).Update("read_at", now).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &notification, nil
+}
+
+// MarkAllRead marks every notification of the user read.
+func (s *NotificationService) MarkAllRead(userID uint) error {
+	db := database.GetDB()
+
+	return db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
+
+// NotifyReleases notifies users of the movies on their watchlist that come
+// out today, in their region or, without regional dates, worldwide.
+func (s *NotificationService) NotifyReleases() error {
+	db := database.GetDB()
+
+	var users []models.User
+	err := db.Where("id IN (SELECT user_id FROM favorite_movies WHERE status = ? AND deleted_at IS NULL)", models.StatusToBe).
+		Find(&users).Error
+	if err != nil {
+		return err
+	}
+
+	today := Today()
+	for i := range users {
+		user := &users[i]
+		entries, err := s.calendarService.GetUpcoming(user, today)
+		if err != nil {
+			log.Printf("Failed to load upcoming releases of user %d: %v", user.ID, err)
+			continue
+		}
+
+		lang := i18n.Resolve(user.Language, "")
+		for _, entry := range entries {
+			if entry.Date.After(today) {
+				break
+			}
+
+			body := i18n.T(lang, entry.Type.Label())
+			if entry.Note != "" {
+				body += " · " + entry.Note
+			}
+			err := s.Notify(user, models.Notification{
+				Type:  models.NotificationRelease,
+				Key:   fmt.Sprintf("release:%d:%s:%d:%s", entry.TMDBId, entry.Region, entry.Type, entry.Date.Format("2006-01-02")),
+				Title: i18n.T(lang, "%s is out today", entry.Title),
+				Body:  body,
+				URL:   fmt.Sprintf("/movie/%d", entry.TMDBId),
+			})
+			if err != nil {
+				log.Printf("Failed to notify user %d of the release of TMDB movie %d: %v", user.ID, entry.TMDBId, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// NotifyStreaming notifies the users with tmdbID on their watchlist in
+// region that it can now be streamed on the providers among started they
+// subscribe to.
+func (s *NotificationService) NotifyStreaming(tmdbID int, region string, started []models.MovieProvider) error {
+	db := database.GetDB()
+
+	var favorites []models.FavoriteMovie
+	err := db.Preload("User").
+		Where("tmdb_id = ? AND status = ? AND user_id IN (SELECT id FROM users WHERE region = ? AND deleted_at IS NULL)",
+			tmdbID, models.StatusToBe, region).
+		Find(&favorites).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range favorites {
+		user := &favorites[i].User
+
+		subscribed := make(map[int]bool, len(user.StreamingServices))
+		for _, providerID := range user.StreamingServices {
+			subscribed[providerID] = true
+		}
+		var ids, names []string
+		for _, provider := range started {
+			if subscribed[provider.ProviderID] {
+				ids = append(ids, fmt.Sprint(provider.ProviderID))
+				names = append(names, provider.ProviderName)
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+
+		lang := i18n.Resolve(user.Language, "")
+		err := s.Notify(user, models.Notification{
+			Type:  models.NotificationStreaming,
+			Key:   fmt.Sprintf("streaming:%d:%s:%s", tmdbID, region, strings.Join(ids, ",")),
+			Title: i18n.T(lang, "%s is now streaming on %s", favorites[i].Title, strings.Join(names, ", ")),
+			URL:   fmt.Sprintf("/movie/%d", tmdbID),
+		})
+		if err != nil {
+			log.Printf("Failed to notify user %d that TMDB movie %d is streaming: %v", user.ID, tmdbID, err)
+		}
+	}
+
+	return nil
+}
+
+// SendDigests emails the users who opted into the weekly digest the
+// notifications they got since their last one, at most once a week.
+func (s *NotificationService) SendDigests() error {
+	db := database.GetDB()
+
+	now := time.Now()
+	var users []models.User
+	err := db.Where("weekly_digest AND (digest_sent_at IS NULL OR digest_sent_at < ?)", now.Add(-digestPeriod)).
+		Find(&users).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range users {
+		if err := s.sendDigest(&users[i], now); err != nil {
+			log.Printf("Failed to send the weekly digest to user %d: %v", users[i].ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *NotificationService) sendDigest(user *models.User, now time.Time) error {
+	db := database.GetDB()
+
+	var notifications []models.Notification
+	err := db.Where("user_id = ? AND digested_at IS NULL AND created_at > ?", user.ID, now.Add(-digestPeriod)).
+		Order("created_at ASC").
+		Find(&notifications).Error
+	if err != nil {
+		return err
+	}
+
+	if len(notifications) > 0 {
+		lang := i18n.Resolve(user.Language, "")
+
+		var body strings.Builder
+		body.WriteString(i18n.T(lang, "Here is what happened this week:"))
+		body.WriteString("\n")
+		ids := make([]uint, 0, len(notifications))
+		for _, notification := range notifications {
+			ids = append(ids, notification.ID)
+			body.WriteString("\n• " + notification.Title)
+			if notification.Body != "" {
+				body.WriteString("\n  " + notification.Body)
+			}
+			if notification.URL != "" {
+				body.WriteString("\n  " + AppURL() + notification.URL)
+			}
+		}
+
+		if err := s.mailer.Send(user.Email, i18n.T(lang, "Your week in Movie Tracker"), body.String()); err != nil {
+			return err
+		}
+		if err := db.Model(&models.Notification{}).Where("id IN ?", ids).Update("digested_at", now).Error; err != nil {
+			return err
+		}
+	}
+
+	// Also recorded without notifications, so the user is checked again in
+	// a week rather than on every run
+	return db.Model(user).UpdateColumn("digest_sent_at", now).Error
+}
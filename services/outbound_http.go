@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// allowPrivateAddresses lets user-supplied URLs (webhooks and notification
+// webhooks) reach private, loopback and other internal addresses. Off by
+// default so users can't make the server call into its own network.
+var allowPrivateAddresses = false
+
+// SetAllowPrivateWebhooks sets whether user-supplied URLs may reach internal
+// addresses (from WEBHOOK_ALLOW_PRIVATE). Call it at startup.
+func SetAllowPrivateWebhooks(allow bool) {
+	allowPrivateAddresses = allow
+}
+
+var errInternalAddress = errors.New("webhook URL must point to a public address")
+
+// internalPrefixes are the non-public ranges netip.Addr has no method for.
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"),  // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // Benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // Reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, which can reach IPv4 internal ranges
+	netip.MustParsePrefix("64:ff9b:1::/48"), // Local-use NAT64
+	netip.MustParsePrefix("2001::/32"),      // Teredo
+	netip.MustParsePrefix("2002::/16"),      // 6to4
+}
+
+// isInternalAddress reports whether addr is not a public unicast address.
+func isInternalAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		// Covers loopback, link-local, multicast and unspecified too
+		return true
+	}
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOutboundURL checks that rawURL is an http(s) URL the server may
+// call. Hostnames are checked again when they are resolved, on every
+// connection, as they may point somewhere else by then.
+func validateOutboundURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("invalid webhook URL")
+	}
+	if allowPrivateAddresses {
+		return nil
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errInternalAddress
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && isInternalAddress(addr) {
+		return errInternalAddress
+	}
+	return nil
+}
+
+// newOutboundClient returns the HTTP client for user-supplied URLs. It
+// checks the address of every connection it opens, redirects included, so
+// hostnames resolving to internal addresses are refused.
+func newOutboundClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkOutboundAddress,
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			// No proxy: the address checked must be the receiver's
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// checkOutboundAddress is a net.Dialer Control function refusing internal
+// addresses, unless they are allowed.
+func checkOutboundAddress(network, address string, _ syscall.RawConn) error {
+	if allowPrivateAddresses {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if isInternalAddress(addr) {
+		return fmt.Errorf("refusing to connect to internal address %s", addr)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// allowPrivate sets allowPrivateAddresses for the rest of the test.
+func allowPrivate(t *testing.T, allow bool) {
+	previous := allowPrivateAddresses
+	SetAllowPrivateWebhooks(allow)
+	t.Cleanup(func() { SetAllowPrivateWebhooks(previous) })
+}
+
+func TestIsInternalAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"127.1.2.3", true},
+		{"10.0.0.1", true},
+		{"172.16.0.1", true},
+		{"172.31.255.255", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"0.0.0.0", true},
+		{"100.64.0.1", true},
+		{"255.255.255.255", true},
+		{"224.0.0.1", true},
+		{"::1", true},
+		{"::", true},
+		{"fc00::1", true},
+		{"fd12:3456::1", true},
+		{"fe80::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"64:ff9b::7f00:1", true},
+		{"8.8.8.8", false},
+		{"172.32.0.1", false},
+		{"93.184.216.34", false},
+		{"::ffff:8.8.8.8", false},
+		{"2606:4700:4700::1111", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := isInternalAddress(netip.MustParseAddr(tt.addr)); got != tt.want {
+				t.Errorf("isInternalAddress(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOutboundURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		url          string
+		allowPrivate bool
+		wantErr      bool
+	}{
+		{name: "public https", url: "https://example.com/hook"},
+		{name: "public http", url: "http://example.com:8080/hook"},
+		{name: "public address", url: "https://8.8.8.8/hook"},
+		{name: "loopback", url: "http://127.0.0.1/hook", wantErr: true},
+		{name: "localhost", url: "http://localhost:8080/hook", wantErr: true},
+		{name: "localhost subdomain", url: "http://api.LOCALHOST/hook", wantErr: true},
+		{name: "RFC1918", url: "http://192.168.0.10/hook", wantErr: true},
+		{name: "cloud metadata", url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{name: "IPv6 loopback", url: "http://[::1]:8080/hook", wantErr: true},
+		{name: "IPv6 unique local", url: "http://[fc00::1]/hook", wantErr: true},
+		{name: "IPv4-mapped loopback", url: "http://[::ffff:127.0.0.1]/hook", wantErr: true},
+		{name: "unspecified", url: "http://0.0.0.0/hook", wantErr: true},
+		{name: "file scheme", url: "file:///etc/passwd", wantErr: true},
+		{name: "ftp scheme", url: "ftp://example.com/hook", wantErr: true},
+		{name: "gopher scheme", url: "gopher://example.com/hook", wantErr: true},
+		{name: "no host", url: "http:///hook", wantErr: true},
+		{name: "not a URL", url: "://example.com", wantErr: true},
+		{name: "allowed loopback", url: "http://127.0.0.1/hook", allowPrivate: true},
+		{name: "allowed localhost", url: "http://localhost:8080/hook", allowPrivate: true},
+		{name: "allowed RFC1918", url: "http://10.0.0.1/hook", allowPrivate: true},
+		{name: "allowed still needs http", url: "file:///etc/passwd", allowPrivate: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowPrivate(t, tt.allowPrivate)
+			err := validateOutboundURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateOutboundURL(%q) = %v, want error %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckOutboundAddress(t *testing.T) {
+	tests := []struct {
+		name         string
+		address      string
+		allowPrivate bool
+		wantErr      bool
+	}{
+		{name: "public", address: "8.8.8.8:443"},
+		{name: "public IPv6", address: "[2606:4700:4700::1111]:443"},
+		{name: "loopback", address: "127.0.0.1:80", wantErr: true},
+		{name: "RFC1918", address: "172.16.5.4:80", wantErr: true},
+		{name: "cloud metadata", address: "169.254.169.254:80", wantErr: true},
+		{name: "IPv6 loopback", address: "[::1]:80", wantErr: true},
+		{name: "IPv6 unique local", address: "[fd00::1]:80", wantErr: true},
+		{name: "IPv4-mapped loopback", address: "[::ffff:127.0.0.1]:80", wantErr: true},
+		{name: "unspecified", address: "0.0.0.0:80", wantErr: true},
+		{name: "no port", address: "8.8.8.8", wantErr: true},
+		{name: "allowed loopback", address: "127.0.0.1:80", allowPrivate: true},
+		{name: "allowed IPv6 loopback", address: "[::1]:80", allowPrivate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowPrivate(t, tt.allowPrivate)
+			err := checkOutboundAddress("tcp", tt.address, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkOutboundAddress(%q) = %v, want error %v", tt.address, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOutboundClientRefusesInternalHostnames(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// Stands for any hostname resolving to an internal address: the client
+	// alone doesn't check URLs, so only the dial can refuse it
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := "http://localhost:" + serverURL.Port() + "/hook"
+
+	t.Run("refused", func(t *testing.T) {
+		allowPrivate(t, false)
+		resp, err := newOutboundClient().Get(target)
+		if err == nil {
+			resp.Body.Close()
+			t.Fatalf("GET %s succeeded, want it refused", target)
+		}
+		if !strings.Contains(err.Error(), "refusing to connect to internal address") {
+			t.Errorf("GET %s = %v, want the internal address refusal", target, err)
+		}
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		allowPrivate(t, true)
+		resp, err := newOutboundClient().Get(target)
+		if err != nil {
+			t.Fatalf("GET %s = %v, want it allowed", target, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("GET %s = %d, want %d", target, resp.StatusCode, http.StatusNoContent)
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"movie-tracker/database"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SnoozeDuration is how long "not tonight" keeps a movie out of the picker.
+const SnoozeDuration = 24 * time.Hour
+
+// ErrNothingToPick is returned when no movie on the watchlist matches the
+// picker constraints.
+var ErrNothingToPick = errors.New("no movie on your watchlist matches tonight's constraints")
+
+type PickerService struct{}
+
+func NewPickerService() *PickerService {
+	return &PickerService{}
+}
+
+// PickerConstraints narrows down the movies the picker may choose from.
+// Empty fields are not applied.
+type PickerConstraints struct {
+	MaxRuntime    *int
+	IncludeGenres []int // The movie must have at least one of these genres
+	ExcludeGenres []int
+	// Moods are tag names; the movie must carry at least one of them
+	Moods         []string
+	Decade        *int // First year of the release decade, e.g. 1990
+	RecommendedBy string
+}
+
+// PickResult is the movie chosen for tonight and why it was favoured.
+type PickResult struct {
+	Favorite   models.FavoriteMovie `json:"favorite"`
+	Reasons    []string             `json:"reasons"`
+	Candidates int                  `json:"candidates"`
+}
+
+// PickerOptions are the values the picker form offers for the user.
+type PickerOptions struct {
+	Moods        []string `json:"moods"`
+	Recommenders []string `json:"recommenders"`
+}
+
+// Pick chooses one of the user's to-watch movies that matches constraints.
+// The choice is random but weighted towards movies that have waited longest,
+// have a higher priority or are rated highly on TMDB. The reasons for the
+// choice are given in lang.
+func (s *PickerService) Pick(userID uint, constraints PickerConstraints, lang string) (*PickResult, error) {
+	db := database.GetDB()
+	var candidates []models.FavoriteMovie
+
+	query := s.candidatesQuery(db, userID, constraints)
+	if err := query.Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNothingToPick
+	}
+
+	now := time.Now()
+	weights := make([]float64, len(candidates))
+	total := 0.0
+	for i := range candidates {
+		weights[i] = pickWeight(&candidates[i], now)
+		total += weights[i]
+	}
+
+	chosen := len(candidates) - 1
+	target := rand.Float64() * total
+	for i, weight := range weights {
+		if target < weight {
+			chosen = i
+			break
+		}
+		target -= weight
+	}
+
+	favorite := candidates[chosen]
+	return &PickResult{
+		Favorite:   favorite,
+		Reasons:    pickReasons(&favorite, now, lang),
+		Candidates: len(candidates),
+	}, nil
+}
+
+// Snooze keeps a movie out of the picker for SnoozeDuration.
+func (s *PickerService) Snooze(id, userID uint) (*models.FavoriteMovie, error) {
+	db := database.GetDB()
+	var favorite models.FavoriteMovie
+
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&favorite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("favorite movie not found")
+		}
+		return nil, err
+	}
+
+	until := time.Now().Add(SnoozeDuration)
+	if err := db.Model(&favorite).UpdateColumn("snoozed_until", until).Error; err != nil {
+		return nil, err
+	}
+	favorite.SnoozedUntil = &until
+
+	return &favorite, nil
+}
+
+// GetOptions lists the user's tags and recommenders for the picker form.
+func (s *PickerService) GetOptions(userID uint) (PickerOptions, error) {
+	db := database.GetDB()
+	var options PickerOptions
+
+	err := db.Model(&models.Tag{}).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Pluck("name", &options.Moods).Error
+	if err != nil {
+		return options, err
+	}
+
+	err = db.Model(&models.FavoriteMovie{}).
+		Where("user_id = ? AND status = ? AND recommended_by <> ''", userID, models.StatusToBe).
+		Distinct("recommended_by").
+		Order("recommended_by ASC").
+		Pluck("recommended_by", &options.Recommenders).Error
+	if err != nil {
+		return options, err
+	}
+
+	return options, nil
+}
+
+func (s *PickerService) candidatesQuery(db *gorm.DB, userID uint, constraints PickerConstraints) *gorm.DB {
+	query := db.Where("user_id = ? AND status = ?", userID, models.StatusToBe).
+		Where("snoozed_until IS NULL OR snoozed_until < ?", time.Now())
+
+	if constraints.MaxRuntime != nil {
+		query = query.Where("runtime IS NOT NULL AND runtime <= ?", *constraints.MaxRuntime)
+	}
+
+	if len(constraints.IncludeGenres) > 0 {
+		var clauses []string
+		var args []interface{}
+		for _, id := range constraints.IncludeGenres {
+			clauses = append(clauses, "genre_ids @> ?::jsonb")
+			args = append(args, fmt.Sprintf("[%d]", id))
+		}
+		query = query.Where(strings.Join(clauses, " OR "), args...)
+	}
+	for _, id := range constraints.ExcludeGenres {
+		query = query.Where("NOT (genre_ids @> ?::jsonb)", fmt.Sprintf("[%d]", id))
+	}
+
+	if len(constraints.Moods) > 0 {
+		query = query.Where(`id IN (
+			SELECT fmt.favorite_movie_id FROM favorite_movie_tags fmt
+			JOIN tags ON tags.id = fmt.tag_id
+			WHERE tags.user_id = ? AND tags.name IN ?)`, userID, constraints.Moods)
+	}
+
+	if constraints.Decade != nil {
+		from := time.Date(*constraints.Decade, 1, 1, 0, 0, 0, 0, time.UTC)
+		query = query.Where("release_date >= ? AND release_date < ?", from, from.AddDate(10, 0, 0))
+	}
+
+	if recommendedBy := strings.TrimSpace(constraints.RecommendedBy); recommendedBy != "" {
+		query = query.Where("LOWER(recommended_by) = LOWER(?)", recommendedBy)
+	}
+
+	return query
+}
+
+// pickWeight scores a candidate. Every movie starts at 1 so nothing is ruled
+// out; waiting time adds up to 4 after a year, each priority level adds 2 and
+// the TMDB vote adds up to 5.
+func pickWeight(favorite *models.FavoriteMovie, now time.Time) float64 {
+	weight := 1.0
+	weight += math.Min(now.Sub(favorite.AddedAt).Hours()/24/90, 4)
+	weight += float64(favorite.Priority) * 2
+	weight += favorite.VoteAverage / 2
+	return weight
+}
+
+func pickReasons(favorite *models.FavoriteMovie, now time.Time, lang string) []string {
+	var reasons []string
+
+	if def, ok := models.GetPriorityDefinition(favorite.Priority); ok && favorite.Priority > models.PriorityNormal {
+		reasons = append(reasons, i18n.T(lang, "%s %s priority", def.Icon, i18n.T(lang, def.Label)))
+	}
+	if months := int(now.Sub(favorite.AddedAt).Hours() / 24 / 30); months >= 1 {
+		if months == 1 {
+			reasons = append(reasons, i18n.T(lang, "On your list for a month"))
+		} else {
+			reasons = append(reasons, i18n.T(lang, "On your list for %d months", months))
+		}
+	}
+	if favorite.VoteAverage >= 7 {
+		reasons = append(reasons, i18n.T(lang, "Rated %.1f on TMDB", favorite.VoteAverage))
+	}
+	if favorite.RecommendedBy != "" {
+		reasons = append(reasons, i18n.T(lang, "Recommended by %s", favorite.RecommendedBy))
+	}
+
+	return reasons
+}
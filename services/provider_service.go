@@ -0,0 +1,328 @@
+package services
+
+import (
+	"log"
+	"movie-tracker/database"
+	"movie-tracker/models"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// providerCache remembers TMDB's watch provider regions and each region's
+// provider list, which rarely change, for the life of the process.
+var providerCache = struct {
+	sync.RWMutex
+	regions   []models.TMDBRegion
+	providers map[string][]models.TMDBWatchProvider
+}{providers: make(map[string][]models.TMDBWatchProvider)}
+
+// StreamingServices are the providers a user subscribes to in their region.
+type StreamingServices struct {
+	Region      string
+	ProviderIDs []int
+}
+
+// UserStreamingServices returns the streaming services set in user's
+// settings.
+func UserStreamingServices(user *models.User) StreamingServices {
+	return StreamingServices{Region: user.Region, ProviderIDs: user.StreamingServices}
+}
+
+// ProviderService keeps track of where the movies on users' watchlists can
+// be streamed. Providers are cached per region in movie_providers and only
+// for the regions users have chosen.
+type ProviderService struct {
+	tmdbService         *TMDBService
+	notificationService *NotificationService
+}
+
+func NewProviderService() *ProviderService {
+	return &ProviderService{
+		tmdbService:         NewTMDBService(),
+		notificationService: NewNotificationService(),
+	}
+}
+
+// GetRegions returns the regions with provider data, sorted by name.
+func (s *ProviderService) GetRegions() ([]models.TMDBRegion, error) {
+	providerCache.RLock()
+	regions := providerCache.regions
+	providerCache.RUnlock()
+	if regions != nil {
+		return regions, nil
+	}
+
+	regions, err := s.tmdbService.GetProviderRegions()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].EnglishName < regions[j].EnglishName })
+
+	providerCache.Lock()
+	providerCache.regions = regions
+	providerCache.Unlock()
+
+	return regions, nil
+}
+
+// GetProviders returns the providers available in region, most popular
+// first.
+func (s *ProviderService) GetProviders(region string) ([]models.TMDBWatchProvider, error) {
+	providerCache.RLock()
+	providers, ok := providerCache.providers[region]
+	providerCache.RUnlock()
+	if ok {
+		return providers, nil
+	}
+
+	providers, err := s.tmdbService.GetProviders(region)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(providers, func(i, j int) bool { return providers[i].DisplayPriority < providers[j].DisplayPriority })
+
+	providerCache.Lock()
+	providerCache.providers[region] = providers
+	providerCache.Unlock()
+
+	return providers, nil
+}
+
+// staleProviders is a watchlist movie whose providers need fetching for a
+// region.
+type staleProviders struct {
+	TMDBId int
+	Region string
+}
+
+// RefreshStale refreshes up to batchSize (movie, region) pairs whose
+// providers have never been fetched or are older than maxAge, least recently
+// tried first, so movies TMDB keeps failing on don't block the rest. Only
+// movies on the watchlist of a user who chose a region and at least one
+// service are considered. Each movie is fetched once for all its regions,
+// waiting delay between TMDB requests.
+func (s *ProviderService) RefreshStale(batchSize int, maxAge, delay time.Duration) error {
+	db := database.GetDB()
+
+	var stale []staleProviders
+	err := db.Raw(`
+		SELECT f.tmdb_id, u.region
+		FROM favorite_movies f
+		JOIN users u ON u.id = f.user_id
+		LEFT JOIN movie_provider_syncs ps ON ps.tmdb_id = f.tmdb_id AND ps.region = u.region
+		WHERE f.deleted_at IS NULL AND f.status = ?
+			AND u.deleted_at IS NULL AND u.region <> ''
+			AND jsonb_array_length(u.streaming_services) > 0
+			AND (ps.synced_at IS NULL OR ps.synced_at < ?)
+		GROUP BY f.tmdb_id, u.region
+		ORDER BY MIN(ps.tried_at) NULLS FIRST, MIN(ps.synced_at) NULLS FIRST
+		LIMIT ?`, models.StatusToBe, time.Now().Add(-maxAge), batchSize).
+		Scan(&stale).Error
+	if err != nil {
+		return err
+	}
+
+	var tmdbIDs []int
+	regions := make(map[int][]string)
+	for _, row := range stale {
+		if _, ok := regions[row.TMDBId]; !ok {
+			tmdbIDs = append(tmdbIDs, row.TMDBId)
+		}
+		regions[row.TMDBId] = append(regions[row.TMDBId], row.Region)
+	}
+
+	for i, tmdbID := range tmdbIDs {
+		if i > 0 {
+			time.Sleep(delay)
+		}
+		now := time.Now()
+		tries := make([]models.MovieProviderSync, 0, len(regions[tmdbID]))
+		for _, region := range regions[tmdbID] {
+			tries = append(tries, models.MovieProviderSync{TMDBId: tmdbID, Region: region, TriedAt: &now})
+		}
+		err := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tmdb_id"}, {Name: "region"}},
+			DoUpdates: clause.AssignmentColumns([]string{"tried_at"}),
+		}).Create(&tries).Error
+		if err != nil {
+			return err
+		}
+		if err := s.RefreshMovie(tmdbID, regions[tmdbID]); err != nil {
+			log.Printf("Failed to refresh watch providers for TMDB movie %d: %v", tmdbID, err)
+		}
+	}
+
+	return nil
+}
+
+// RefreshMovie replaces the cached providers of tmdbID in each of regions,
+// and notifies the users who can now stream it on one of their services.
+// Regions fetched for the first time have nothing to compare with, so they
+// don't notify.
+func (s *ProviderService) RefreshMovie(tmdbID int, regions []string) error {
+	db := database.GetDB()
+
+	response, err := s.tmdbService.GetWatchProviders(tmdbID)
+	if err != nil {
+		return err
+	}
+
+	var previous []models.MovieProvider
+	err = db.Where("tmdb_id = ? AND region IN ? AND type IN ?", tmdbID, regions, models.StreamingProviderTypes).
+		Find(&previous).Error
+	if err != nil {
+		return err
+	}
+	var previousSyncs []models.MovieProviderSync
+	err = db.Where("tmdb_id = ? AND region IN ? AND synced_at IS NOT NULL", tmdbID, regions).Find(&previousSyncs).Error
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var providers []models.MovieProvider
+	syncs := make([]models.MovieProviderSync, 0, len(regions))
+	for _, region := range regions {
+		for providerType, list := range response.Results[region].ByType() {
+			for _, provider := range list {
+				providers = append(providers, models.MovieProvider{
+					TMDBId:          tmdbID,
+					Region:          region,
+					ProviderID:      provider.ProviderID,
+					Type:            providerType,
+					ProviderName:    provider.ProviderName,
+					LogoPath:        provider.LogoPath,
+					DisplayPriority: provider.DisplayPriority,
+				})
+			}
+		}
+		syncs = append(syncs, models.MovieProviderSync{TMDBId: tmdbID, Region: region, SyncedAt: &now})
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tmdb_id = ? AND region IN ?", tmdbID, regions).Delete(&models.MovieProvider{}).Error; err != nil {
+			return err
+		}
+		if len(providers) > 0 {
+			// TMDB occasionally lists a provider twice under one type
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&providers).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "tmdb_id"}, {Name: "region"}},
+			DoUpdates: clause.AssignmentColumns([]string{"synced_at"}),
+		}).Create(&syncs).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	for region, started := range startedStreaming(previousSyncs, previous, providers) {
+		if err := s.notificationService.NotifyStreaming(tmdbID, region, started); err != nil {
+			log.Printf("Failed to notify that TMDB movie %d is streaming in %s: %v", tmdbID, region, err)
+		}
+	}
+
+	return nil
+}
+
+// startedStreaming returns, per region synced before, the providers in
+// current that stream the movie and weren't among previous.
+func startedStreaming(previousSyncs []models.MovieProviderSync, previous, current []models.MovieProvider) map[string][]models.MovieProvider {
+	synced := make(map[string]bool, len(previousSyncs))
+	for _, previousSync := range previousSyncs {
+		synced[previousSync.Region] = true
+	}
+	streamed := make(map[string]map[int]bool)
+	for _, provider := range previous {
+		if streamed[provider.Region] == nil {
+			streamed[provider.Region] = make(map[int]bool)
+		}
+		streamed[provider.Region][provider.ProviderID] = true
+	}
+
+	started := make(map[string][]models.MovieProvider)
+	for _, provider := range current {
+		if !synced[provider.Region] || !isStreamingType(provider.Type) || streamed[provider.Region][provider.ProviderID] {
+			continue
+		}
+		// Also marks providers listed under several streaming types
+		if streamed[provider.Region] == nil {
+			streamed[provider.Region] = make(map[int]bool)
+		}
+		streamed[provider.Region][provider.ProviderID] = true
+		started[provider.Region] = append(started[provider.Region], provider)
+	}
+	return started
+}
+
+func isStreamingType(providerType string) bool {
+	for _, streamingType := range models.StreamingProviderTypes {
+		if providerType == streamingType {
+			return true
+		}
+	}
+	return false
+}
+
+// GetStreamingOn returns, for each of tmdbIDs that can be streamed right now
+// on one of the subscribed services, the providers it is on. Movies that
+// cannot be streamed are missing from the map.
+func (s *ProviderService) GetStreamingOn(subscribed StreamingServices, tmdbIDs []int) (map[int][]models.MovieProvider, error) {
+	streaming := make(map[int][]models.MovieProvider)
+	if subscribed.Region == "" || len(subscribed.ProviderIDs) == 0 || len(tmdbIDs) == 0 {
+		return streaming, nil
+	}
+
+	db := database.GetDB()
+	var rows []models.MovieProvider
+	err := db.Where("tmdb_id IN ? AND region = ? AND provider_id IN ? AND type IN ?",
+		tmdbIDs, subscribed.Region, subscribed.ProviderIDs, models.StreamingProviderTypes).
+		Order("display_priority ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// A provider can offer a movie both with a subscription and with ads
+	seen := make(map[[2]int]bool)
+	for _, row := range rows {
+		key := [2]int{row.TMDBId, row.ProviderID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		streaming[row.TMDBId] = append(streaming[row.TMDBId], row)
+	}
+
+	return streaming, nil
+}
+
+// FillStreamingOn sets StreamingOn on the watchlist movies among favorites
+// that can be streamed on one of the subscribed services.
+func (s *ProviderService) FillStreamingOn(subscribed StreamingServices, favorites []models.FavoriteMovie) error {
+	var tmdbIDs []int
+	for _, favorite := range favorites {
+		if favorite.Status == models.StatusToBe {
+			tmdbIDs = append(tmdbIDs, favorite.TMDBId)
+		}
+	}
+
+	streaming, err := s.GetStreamingOn(subscribed, tmdbIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range favorites {
+		if favorites[i].Status == models.StatusToBe {
+			favorites[i].StreamingOn = streaming[favorites[i].TMDBId]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"movie-tracker/database"
+	"movie-tracker/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrPublicPageNotFound is returned for pages that don't exist and for the
+// ones their owner doesn't share, so visitors can't tell them apart.
+var ErrPublicPageNotFound = errors.New("page not found")
+
+// PublicList is a list shown on a public page, with how many movies it has.
+type PublicList struct {
+	models.MovieList
+	MovieCount int
+}
+
+// PublicListPage is a shared list with its owner and movies.
+type PublicListPage struct {
+	Owner  *models.User
+	List   *models.MovieList
+	Movies []models.FavoriteMovie
+	// Unlisted is set when the page was opened with the list's share
+	// link rather than from its owner's public page
+	Unlisted bool
+}
+
+// PublicPageService serves the pages users share outside the app: their
+// public page at /u/<username>, with their public lists and optionally
+// their ratings, and lists shared by link.
+type PublicPageService struct{}
+
+func NewPublicPageService() *PublicPageService {
+	return &PublicPageService{}
+}
+
+// GetUser returns the user with username if their public page is on.
+func (s *PublicPageService) GetUser(username string) (*models.User, error) {
+	db := database.GetDB()
+
+	var user models.User
+	err := db.Where("username = ? AND public_page = ?", username, true).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPublicPageNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetPublicLists returns userID's public lists by name, with their movie
+// counts.
+func (s *PublicPageService) GetPublicLists(userID uint) ([]PublicList, error) {
+	db := database.GetDB()
+	var lists []PublicList
+
+	err := db.Model(&models.MovieList{}).
+		Select("movie_lists.*, (SELECT COUNT(*) FROM favorite_movie_lists JOIN favorite_movies ON favorite_movies.id = favorite_movie_lists.favorite_movie_id AND favorite_movies.deleted_at IS NULL WHERE favorite_movie_lists.movie_list_id = movie_lists.id) AS movie_count").
+		Where("user_id = ? AND visibility = ?", userID, models.ListPublic).
+		Order("name ASC").
+		Scan(&lists).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return lists, nil
+}
+
+// GetRatings returns up to limit of userID's rated movies, most recently
+// watched first.
+func (s *PublicPageService) GetRatings(userID uint, limit int) ([]models.FavoriteMovie, error) {
+	db := database.GetDB()
+	var favorites []models.FavoriteMovie
+
+	err := db.Where("user_id = ? AND rating IS NOT NULL", userID).
+		Order("COALESCE(watched_at, added_at) DESC").
+		Limit(limit).
+		Find(&favorites).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return favorites, nil
+}
+
+// GetList returns the list slug of username with its movies by title. A
+// public list is shown while its owner's public page is on; an unlisted or
+// public one is also shown to anyone with its share token.
+func (s *PublicPageService) GetList(username, slug, token string) (*PublicListPage, error) {
+	db := database.GetDB()
+
+	var owner models.User
+	if err := db.Where("username = ?", username).First(&owner).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPublicPageNotFound
+		}
+		return nil, err
+	}
+
+	var list models.MovieList
+	err := db.Where("user_id = ? AND slug = ? AND visibility <> ?", owner.ID, slug, models.ListPrivate).First(&list).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPublicPageNotFound
+		}
+		return nil, err
+	}
+
+	page := &PublicListPage{Owner: &owner, List: &list}
+	switch {
+	case token != "" && list.ShareToken != nil && subtle.ConstantTimeCompare([]byte(token), []byte(*list.ShareToken)) == 1:
+		page.Unlisted = list.Visibility == models.ListUnlisted || !owner.PublicPage
+	case list.Visibility == models.ListPublic && owner.PublicPage:
+	default:
+		return nil, ErrPublicPageNotFound
+	}
+
+	err = db.Joins("JOIN favorite_movie_lists ON favorite_movie_lists.favorite_movie_id = favorite_movies.id").
+		Where("favorite_movie_lists.movie_list_id = ?", list.ID).
+		Order("favorite_movies.title ASC").
+		Find(&page.Movies).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// GetLists returns all of userID's lists by name, for choosing who sees
+// each.
+func (s *PublicPageService) GetLists(userID uint) ([]models.MovieList, error) {
+	db := database.GetDB()
+	var lists []models.MovieList
+
+	if err := db.Where("user_id = ?", userID).Order("name ASC").Find(&lists).Error; err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+// SetListVisibility changes who can see one of userID's lists. Lists that
+// stop being private get a share token, kept if they become private again
+// so old links work once they are shared again.
+func (s *PublicPageService) SetListVisibility(listID, userID uint, visibility models.ListVisibility) (*models.MovieList, error) {
+	if !validListVisibility(visibility) {
+		return nil, errors.New("invalid visibility")
+	}
+
+	list, err := s.getList(listID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{"visibility": visibility}
+	if visibility != models.ListPrivate && list.ShareToken == nil {
+		token, err := newShareToken()
+		if err != nil {
+			return nil, err
+		}
+		updates["share_token"] = &token
+	}
+
+	db := database.GetDB()
+	if err := db.Model(list).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ResetListToken gives one of userID's lists a new share token, so the
+// previous share link stops working.
+func (s *PublicPageService) ResetListToken(listID, userID uint) (*models.MovieList, error) {
+	list, err := s.getList(listID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB()
+	if err := db.Model(list).Update("share_token", &token).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *PublicPageService) getList(listID, userID uint) (*models.MovieList, error) {
+	db := database.GetDB()
+
+	var list models.MovieList
+	if err := db.Where("id = ? AND user_id = ?", listID, userID).First(&list).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("list not found")
+		}
+		return nil, err
+	}
+	return &list, nil
+}
+
+func validListVisibility(visibility models.ListVisibility) bool {
+	for _, v := range models.ListVisibilities {
+		if v == visibility {
+			return true
+		}
+	}
+	return false
+}
+
+func newShareToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
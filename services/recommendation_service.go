@@ -0,0 +1,489 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"movie-tracker/database"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+const (
+	// similarSeeds is how many of the user's best rated movies are used to
+	// fetch "similar" candidates from TMDB.
+	similarSeeds = 3
+	// profileCompanyMovies caps the TMDB detail lookups made to learn which
+	// production companies the user likes.
+	profileCompanyMovies = 20
+	// enrichedCandidates is how many of the best candidates get their
+	// production companies looked up before the final ranking.
+	enrichedCandidates = 15
+	// recommendationsTTL is how long a user's ranked candidates are reused
+	// before TMDB is asked again.
+	recommendationsTTL = time.Hour
+	// maxCachedCompanies bounds companyCache.
+	maxCachedCompanies = 10000
+)
+
+// Weights of each part of a candidate's score.
+const (
+	genreWeight    = 0.4
+	companyWeight  = 0.2
+	decadeWeight   = 0.15
+	languageWeight = 0.15
+	similarWeight  = 0.3
+	voteWeight     = 0.05
+)
+
+// companyCache remembers the production companies of TMDB movies, which
+// rarely change, so each movie is looked up about once per process. It
+// holds up to maxCachedCompanies movies, evicting one at random when full.
+var companyCache = struct {
+	sync.RWMutex
+	companies map[int][]models.ProductionCompany
+}{companies: make(map[int][]models.ProductionCompany)}
+
+// recommendationCache holds each user's ranked recommendations per locale
+// for recommendationsTTL, as computing them takes dozens of TMDB requests.
+var recommendationCache = struct {
+	sync.Mutex
+	entries map[string]cachedRecommendations
+}{entries: make(map[string]cachedRecommendations)}
+
+type cachedRecommendations struct {
+	recommendations []Recommendation
+	expiresAt       time.Time
+}
+
+// Recommendation is a TMDB movie suggested to the user and why.
+type Recommendation struct {
+	Movie       models.TMDBMovie `json:"movie"`
+	Score       float64          `json:"score"`
+	Explanation string           `json:"explanation"`
+}
+
+// preferenceProfile holds how much the user likes each genre, decade,
+// original language and production company, from -1 (dislikes) to 1. Keys
+// are the feature kind and value, e.g. "genre:18" or "language:ja".
+type preferenceProfile struct {
+	sums   map[string]float64
+	counts map[string]int
+}
+
+func newPreferenceProfile() *preferenceProfile {
+	return &preferenceProfile{sums: make(map[string]float64), counts: make(map[string]int)}
+}
+
+func (p *preferenceProfile) add(kind string, value interface{}, weight float64) {
+	key := fmt.Sprintf("%s:%v", kind, value)
+	p.sums[key] += weight
+	p.counts[key]++
+}
+
+// score averages the weights seen for a feature value. One extra neutral
+// observation is added so a value seen once counts less than one seen many
+// times; unseen values score 0.
+func (p *preferenceProfile) score(kind string, value interface{}) float64 {
+	key := fmt.Sprintf("%s:%v", kind, value)
+	return p.sums[key] / float64(p.counts[key]+1)
+}
+
+// candidate is a TMDB movie being ranked, with where it came from.
+type candidate struct {
+	movie       models.TMDBMovie
+	source      string
+	similarTo   *models.FavoriteMovie
+	companies   []models.ProductionCompany
+	score       float64
+	explanation string
+}
+
+type RecommendationService struct {
+	tmdbService  *TMDBService
+	genreService *GenreService
+	lang         string // UI language of the explanations
+}
+
+func NewRecommendationService() *RecommendationService {
+	return &RecommendationService{
+		tmdbService:  NewTMDBService(),
+		genreService: NewGenreService(),
+		lang:         i18n.Default,
+	}
+}
+
+// WithLocale returns a copy of the service that fetches candidates from TMDB
+// in language and region, as TMDBService.WithLocale, and explains them in
+// the matching UI language.
+func (s *RecommendationService) WithLocale(language, region string) *RecommendationService {
+	localized := *s
+	localized.tmdbService = s.tmdbService.WithLocale(language, region)
+	localized.genreService = s.genreService.WithLanguage(language)
+	localized.lang = i18n.FromTMDBLocale(language)
+	return &localized
+}
+
+// GetRecommendations suggests up to limit movies the user has not tracked
+// yet, ranked by how well they match the genres, decades, languages and
+// production companies of the movies the user rated. Rankings are cached
+// for recommendationsTTL; movies tracked since are left out of them.
+func (s *RecommendationService) GetRecommendations(userID uint, limit int) ([]Recommendation, error) {
+	db := database.GetDB()
+
+	// Anything the user tracks, including the trash, is never suggested
+	var trackedIDs []int
+	if err := db.Unscoped().Model(&models.FavoriteMovie{}).Where("user_id = ?", userID).Pluck("tmdb_id", &trackedIDs).Error; err != nil {
+		return nil, err
+	}
+	tracked := make(map[int]bool, len(trackedIDs))
+	for _, id := range trackedIDs {
+		tracked[id] = true
+	}
+
+	key := fmt.Sprintf("%d:%s:%s", userID, s.tmdbService.language, s.tmdbService.region)
+	ranked, ok := cachedRecommendationsFor(key)
+	if !ok {
+		var err error
+		if ranked, err = s.rank(userID, tracked); err != nil {
+			return nil, err
+		}
+		// Nothing to go on yet: the first rating should show results right away
+		if len(ranked) > 0 {
+			cacheRecommendations(key, ranked)
+		}
+	}
+
+	recommendations := make([]Recommendation, 0, len(ranked))
+	for _, recommendation := range ranked {
+		if limit > 0 && len(recommendations) == limit {
+			break
+		}
+		if !tracked[recommendation.Movie.ID] {
+			recommendations = append(recommendations, recommendation)
+		}
+	}
+
+	return recommendations, nil
+}
+
+// rank scores every candidate for the user, best first.
+func (s *RecommendationService) rank(userID uint, tracked map[int]bool) ([]Recommendation, error) {
+	db := database.GetDB()
+
+	var rated []models.FavoriteMovie
+	if err := db.Where("user_id = ? AND rating IS NOT NULL", userID).Order("rating DESC, updated_at DESC").Find(&rated).Error; err != nil {
+		return nil, err
+	}
+	if len(rated) == 0 {
+		return []Recommendation{}, nil
+	}
+
+	profile := s.buildProfile(rated)
+	candidates := s.collectCandidates(rated, tracked)
+
+	for _, c := range candidates {
+		s.scoreCandidate(c, profile)
+	}
+	sortCandidates(candidates)
+
+	// Companies need one TMDB request per movie, so only the front runners
+	// are looked up and re-ranked with them
+	for i := 0; i < len(candidates) && i < enrichedCandidates; i++ {
+		candidates[i].companies = s.movieCompanies(candidates[i].movie.ID)
+		s.scoreCandidate(candidates[i], profile)
+	}
+	sortCandidates(candidates)
+
+	recommendations := make([]Recommendation, 0, len(candidates))
+	for _, c := range candidates {
+		recommendations = append(recommendations, Recommendation{
+			Movie:       c.movie,
+			Score:       c.score,
+			Explanation: c.explanation,
+		})
+	}
+
+	return recommendations, nil
+}
+
+func (s *RecommendationService) buildProfile(rated []models.FavoriteMovie) *preferenceProfile {
+	profile := newPreferenceProfile()
+
+	for i, favorite := range rated {
+		weight := ratingWeight(*favorite.Rating)
+
+		for _, genreID := range favorite.GenreIDs {
+			profile.add("genre", genreID, weight)
+		}
+		if favorite.ReleaseDate != nil {
+			profile.add("decade", decadeOf(favorite.ReleaseDate.Year()), weight)
+		}
+		if favorite.OriginalLanguage != "" {
+			profile.add("language", favorite.OriginalLanguage, weight)
+		}
+
+		// rated is ordered best first, so the capped lookups cover the
+		// movies that say most about the user's taste
+		if i < profileCompanyMovies {
+			for _, company := range s.movieCompanies(favorite.TMDBId) {
+				profile.add("company", company.ID, weight)
+			}
+		}
+	}
+
+	return profile
+}
+
+func (s *RecommendationService) collectCandidates(rated []models.FavoriteMovie, tracked map[int]bool) []*candidate {
+	seen := make(map[int]*candidate)
+	var candidates []*candidate
+
+	add := func(movies []models.TMDBMovie, source string, similarTo *models.FavoriteMovie) {
+		for _, movie := range movies {
+			if tracked[movie.ID] || movie.Adult {
+				continue
+			}
+			if existing, ok := seen[movie.ID]; ok {
+				if similarTo != nil && existing.similarTo == nil {
+					existing.similarTo = similarTo
+				}
+				continue
+			}
+			c := &candidate{movie: movie, source: source, similarTo: similarTo}
+			seen[movie.ID] = c
+			candidates = append(candidates, c)
+		}
+	}
+
+	for i := 0; i < len(rated) && i < similarSeeds; i++ {
+		seed := &rated[i]
+		if ratingWeight(*seed.Rating) <= 0 {
+			break
+		}
+		similar, err := s.tmdbService.GetSimilarMovies(seed.TMDBId, 1)
+		if err != nil {
+			log.Printf("Failed to get movies similar to %d: %v", seed.TMDBId, err)
+			continue
+		}
+		add(similar.Results, "similar", seed)
+	}
+
+	if trending, err := s.tmdbService.GetTrendingMovies(1); err == nil {
+		add(trending.Results, "trending", nil)
+	} else {
+		log.Printf("Failed to get trending movies: %v", err)
+	}
+
+	if popular, err := s.tmdbService.GetPopularMovies(1); err == nil {
+		add(popular.Results, "popular", nil)
+	} else {
+		log.Printf("Failed to get popular movies: %v", err)
+	}
+
+	return candidates
+}
+
+// explanationPart is one reason a candidate scored well.
+type explanationPart struct {
+	weight float64
+	text   string
+}
+
+func (s *RecommendationService) scoreCandidate(c *candidate, profile *preferenceProfile) {
+	var parts []explanationPart
+
+	// Genres: average affinity, explained by the best liked ones
+	if len(c.movie.GenreIDs) > 0 {
+		total := 0.0
+		var liked []int
+		for _, genreID := range c.movie.GenreIDs {
+			score := profile.score("genre", genreID)
+			total += score
+			if score > 0 {
+				liked = append(liked, genreID)
+			}
+		}
+		sort.Slice(liked, func(i, j int) bool {
+			return profile.score("genre", liked[i]) > profile.score("genre", liked[j])
+		})
+		if len(liked) > 2 {
+			liked = liked[:2]
+		}
+
+		var names []string
+		for _, genreID := range liked {
+			if name := s.genreService.GetGenreName(genreID); name != "" {
+				names = append(names, name)
+			}
+		}
+		part := explanationPart{weight: genreWeight * total / float64(len(c.movie.GenreIDs))}
+		if len(names) > 0 {
+			part.text = i18n.T(s.lang, "you rate %s highly", joinAnd(s.lang, names))
+		}
+		parts = append(parts, part)
+	}
+
+	if year := parseReleaseDate(c.movie.ReleaseDate); year != nil {
+		decade := decadeOf(year.Year())
+		parts = append(parts, explanationPart{
+			weight: decadeWeight * profile.score("decade", decade),
+			text:   i18n.T(s.lang, "you enjoy films from the %ds", decade),
+		})
+	}
+
+	if code := c.movie.OriginalLanguage; code != "" {
+		parts = append(parts, explanationPart{
+			weight: languageWeight * profile.score("language", code),
+			text:   i18n.T(s.lang, "you enjoy films in %s", languageName(code, s.lang)),
+		})
+	}
+
+	if len(c.companies) > 0 {
+		best := c.companies[0]
+		total := 0.0
+		for _, company := range c.companies {
+			total += profile.score("company", company.ID)
+			if profile.score("company", company.ID) > profile.score("company", best.ID) {
+				best = company
+			}
+		}
+		parts = append(parts, explanationPart{
+			weight: companyWeight * total / float64(len(c.companies)),
+			text:   i18n.T(s.lang, "it's from %s, whose films you rate highly", best.Name),
+		})
+	}
+
+	if c.similarTo != nil {
+		parts = append(parts, explanationPart{
+			weight: similarWeight * ratingWeight(*c.similarTo.Rating),
+			text:   i18n.T(s.lang, "it's similar to %s, which you rated %d/10", c.similarTo.Title, *c.similarTo.Rating),
+		})
+	}
+
+	c.score = voteWeight * c.movie.VoteAverage / 10
+	for _, part := range parts {
+		c.score += part.weight
+	}
+
+	sort.SliceStable(parts, func(i, j int) bool { return parts[i].weight > parts[j].weight })
+	var reasons []string
+	for _, part := range parts {
+		if part.weight <= 0 || len(reasons) == 2 {
+			break
+		}
+		if part.text != "" {
+			reasons = append(reasons, part.text)
+		}
+	}
+
+	switch {
+	case len(reasons) > 0:
+		c.explanation = i18n.T(s.lang, "Because %s", joinAnd(s.lang, reasons))
+	case c.source == "trending":
+		c.explanation = i18n.T(s.lang, "Trending this week")
+	default:
+		c.explanation = i18n.T(s.lang, "Popular right now")
+	}
+}
+
+// movieCompanies returns the production companies of a TMDB movie, looking
+// them up once and caching them.
+func (s *RecommendationService) movieCompanies(tmdbID int) []models.ProductionCompany {
+	companyCache.RLock()
+	companies, ok := companyCache.companies[tmdbID]
+	companyCache.RUnlock()
+	if ok {
+		return companies
+	}
+
+	details, err := s.tmdbService.GetMovieFullDetails(tmdbID)
+	if err != nil {
+		log.Printf("Failed to get production companies for %d: %v", tmdbID, err)
+		return nil
+	}
+
+	companyCache.Lock()
+	if len(companyCache.companies) >= maxCachedCompanies {
+		for id := range companyCache.companies {
+			delete(companyCache.companies, id)
+			break
+		}
+	}
+	companyCache.companies[tmdbID] = details.ProductionCompanies
+	companyCache.Unlock()
+
+	return details.ProductionCompanies
+}
+
+func cachedRecommendationsFor(key string) ([]Recommendation, bool) {
+	recommendationCache.Lock()
+	defer recommendationCache.Unlock()
+
+	entry, ok := recommendationCache.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.recommendations, true
+}
+
+// cacheRecommendations stores a ranking, dropping expired ones so the cache
+// only holds users active within recommendationsTTL.
+func cacheRecommendations(key string, recommendations []Recommendation) {
+	recommendationCache.Lock()
+	defer recommendationCache.Unlock()
+
+	now := time.Now()
+	for k, entry := range recommendationCache.entries {
+		if now.After(entry.expiresAt) {
+			delete(recommendationCache.entries, k)
+		}
+	}
+	recommendationCache.entries[key] = cachedRecommendations{
+		recommendations: recommendations,
+		expiresAt:       now.Add(recommendationsTTL),
+	}
+}
+
+func sortCandidates(candidates []*candidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+}
+
+// ratingWeight turns a 1-10 rating into a preference between -0.8 and 1:
+// movies rated 6 or more count in favour, 5 or less against.
+func ratingWeight(rating int) float64 {
+	return float64(rating-5) / 5
+}
+
+func decadeOf(year int) int {
+	return year / 10 * 10
+}
+
+// languageName returns the name in lang of an ISO 639-1 code, or the code
+// itself if it is unknown.
+func languageName(code, lang string) string {
+	tag, err := language.Parse(code)
+	if err != nil {
+		return code
+	}
+	if name := display.Languages(language.Make(lang)).Name(tag); name != "" {
+		return name
+	}
+	return code
+}
+
+// joinAnd lists items in lang, e.g. "a, b and c".
+func joinAnd(lang string, items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return i18n.T(lang, "%s and %s", strings.Join(items[:len(items)-1], ", "), items[len(items)-1])
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"movie-tracker/database"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+// UserSettings are the preferences a user can change on the settings page.
+// Nil fields are left unchanged.
+type UserSettings struct {
+	TasteMatchingOptOut *bool
+	// Language is one of i18n.Supported, or empty to follow the browser
+	Language *string
+	// Region is an ISO 3166-1 country code, empty when not set
+	Region            *string
+	StreamingServices *[]int
+	// PrivateProfile makes follows need the user's approval
+	PrivateProfile *bool
+	// FeedHide* keep kinds of activity out of followers' feeds
+	FeedHideAdded   *bool
+	FeedHideWatched *bool
+	FeedHideRatings *bool
+	// PublicPage shares the user's page at /u/<username>, PublicRatings
+	// shows their ratings there and AllowIndexing lets search engines in
+	PublicPage    *bool
+	PublicRatings *bool
+	AllowIndexing *bool
+}
+
+var regionPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// ErrInvalidRegion and ErrUnsupportedLanguage are returned by UpdateSettings
+// for settings it can't save.
+var (
+	ErrInvalidRegion       = errors.New("invalid region")
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+)
+
+type SettingsService struct {
+	similarityService *SimilarityService
+	followService     *FollowService
+}
+
+func NewSettingsService() *SettingsService {
+	return &SettingsService{
+		similarityService: NewSimilarityService(),
+		followService:     NewFollowService(),
+	}
+}
+
+// UpdateSettings saves settings on user. Opting out of taste matching also
+// drops the user from every stored neighbour list right away. Changing the
+// language marks the user's movies and shows for the metadata and show
+// refreshes, which store their titles in the new language. Watch providers
+// for a new region are fetched by the next provider refresh. Making a private
+// profile public accepts the pending follow requests.
+func (s *SettingsService) UpdateSettings(user *models.User, settings UserSettings) error {
+	if settings.Region != nil && *settings.Region != "" && !regionPattern.MatchString(*settings.Region) {
+		return ErrInvalidRegion
+	}
+	if settings.Language != nil && *settings.Language != "" && !i18n.IsSupported(*settings.Language) {
+		return ErrUnsupportedLanguage
+	}
+	languageChanged := settings.Language != nil && *settings.Language != user.Language
+	madePublic := user.PrivateProfile && settings.PrivateProfile != nil && !*settings.PrivateProfile
+
+	db := database.GetDB()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{}
+		setBool(updates, "taste_matching_opt_out", settings.TasteMatchingOptOut)
+		setBool(updates, "private_profile", settings.PrivateProfile)
+		setBool(updates, "feed_hide_added", settings.FeedHideAdded)
+		setBool(updates, "feed_hide_watched", settings.FeedHideWatched)
+		setBool(updates, "feed_hide_ratings", settings.FeedHideRatings)
+		setBool(updates, "public_page", settings.PublicPage)
+		setBool(updates, "public_ratings", settings.PublicRatings)
+		setBool(updates, "allow_indexing", settings.AllowIndexing)
+		if settings.Language != nil {
+			updates["language"] = *settings.Language
+		}
+		if settings.Region != nil {
+			updates["region"] = *settings.Region
+		}
+		if settings.StreamingServices != nil {
+			updates["streaming_services"] = models.IntArray(*settings.StreamingServices)
+		}
+		if err := tx.Model(user).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if languageChanged {
+			err := tx.Model(&models.FavoriteMovie{}).Where("user_id = ?", user.ID).
+				Updates(map[string]interface{}{"metadata_synced_at": nil, "metadata_tried_at": nil}).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&models.TrackedShow{}).Where("user_id = ?", user.ID).
+				UpdateColumns(map[string]interface{}{"synced_at": nil, "tried_at": nil}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if madePublic {
+			if err := s.followService.AcceptAllRequests(tx, user.ID); err != nil {
+				return err
+			}
+		}
+
+		if settings.TasteMatchingOptOut != nil && *settings.TasteMatchingOptOut {
+			return s.similarityService.RemoveUser(tx, user.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	assignBool(&user.TasteMatchingOptOut, settings.TasteMatchingOptOut)
+	if settings.Language != nil {
+		user.Language = *settings.Language
+	}
+	if settings.Region != nil {
+		user.Region = *settings.Region
+	}
+	if settings.StreamingServices != nil {
+		user.StreamingServices = models.IntArray(*settings.StreamingServices)
+	}
+	assignBool(&user.PrivateProfile, settings.PrivateProfile)
+	assignBool(&user.FeedHideAdded, settings.FeedHideAdded)
+	assignBool(&user.FeedHideWatched, settings.FeedHideWatched)
+	assignBool(&user.FeedHideRatings, settings.FeedHideRatings)
+	assignBool(&user.PublicPage, settings.PublicPage)
+	assignBool(&user.PublicRatings, settings.PublicRatings)
+	assignBool(&user.AllowIndexing, settings.AllowIndexing)
+	return nil
+}
+
+// setBool adds column to updates if value is set.
+func setBool(updates map[string]interface{}, column string, value *bool) {
+	if value != nil {
+		updates[column] = *value
+	}
+}
+
+// assignBool copies value to field if it is set.
+func assignBool(field *bool, value *bool) {
+	if value != nil {
+		*field = *value
+	}
+}
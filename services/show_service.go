@@ -0,0 +1,638 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"movie-tracker/database"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ShowService tracks the TV series on a user's list and which of their
+// episodes have been watched. Episodes are copied from TMDB when a show is
+// added and kept up to date by RefreshStale while the show is still airing.
+type ShowService struct {
+	tmdbService *TMDBService
+}
+
+func NewShowService() *ShowService {
+	return &ShowService{
+		tmdbService: NewTMDBService(),
+	}
+}
+
+// WithLocale returns a copy of the service that fetches shows and episodes
+// from TMDB in language and region, as TMDBService.WithLocale.
+func (s *ShowService) WithLocale(language, region string) *ShowService {
+	localized := *s
+	localized.tmdbService = s.tmdbService.WithLocale(language, region)
+	return &localized
+}
+
+// TrackShow adds a TMDB series to the user's shows and stores its episodes,
+// in the service's language.
+func (s *ShowService) TrackShow(userID uint, tmdbID int) (*models.TrackedShow, error) {
+	db := database.GetDB()
+
+	var existing int64
+	db.Model(&models.TrackedShow{}).Where("user_id = ? AND tmdb_id = ?", userID, tmdbID).Count(&existing)
+	if existing > 0 {
+		return nil, errors.New("show is already on your list")
+	}
+
+	details, err := s.tmdbService.GetShowDetails(tmdbID)
+	if err != nil {
+		return nil, err
+	}
+
+	show := &models.TrackedShow{
+		UserID: userID,
+		TMDBId: tmdbID,
+		Status: models.StatusToBe,
+	}
+	applyShowDetails(show, details)
+
+	if err := db.Create(show).Error; err != nil {
+		return nil, err
+	}
+
+	// The show stays tracked if an episode list fails to load: the refresh
+	// job fills in whatever is missing on its next run
+	if err := s.syncShow([]models.TrackedShow{*show}, details, 0); err != nil {
+		log.Printf("Failed to load episodes for TMDB show %d: %v", tmdbID, err)
+	}
+
+	return show, nil
+}
+
+// GetUserShows returns the user's shows with their episode counts, the ones
+// being watched first.
+func (s *ShowService) GetUserShows(userID uint) ([]models.TrackedShow, error) {
+	db := database.GetDB()
+	var shows []models.TrackedShow
+
+	err := db.Where("user_id = ?", userID).
+		Order("CASE status WHEN 'viendo' THEN 0 WHEN 'por_ver' THEN 1 ELSE 2 END, name ASC").
+		Find(&shows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fillEpisodeCounts(db, shows); err != nil {
+		return nil, err
+	}
+
+	return shows, nil
+}
+
+// GetShowByTMDBID returns the user's copy of a TMDB series with its
+// episodes grouped by season.
+func (s *ShowService) GetShowByTMDBID(userID uint, tmdbID int) (*models.TrackedShow, []models.Season, error) {
+	db := database.GetDB()
+	var show models.TrackedShow
+
+	err := db.Preload("Episodes", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("season_number ASC, episode_number ASC")
+	}).Where("user_id = ? AND tmdb_id = ?", userID, tmdbID).First(&show).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("show not found")
+		}
+		return nil, nil, err
+	}
+
+	seasons := groupSeasons(show.Episodes)
+	for _, season := range seasons {
+		show.WatchedEpisodes += season.Watched
+		show.AiredEpisodes += season.Aired
+	}
+
+	return &show, seasons, nil
+}
+
+// GetSeason returns one season of the user's show.
+func (s *ShowService) GetSeason(id, userID uint, seasonNumber int) (*models.Season, error) {
+	show, err := s.getShow(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB()
+	var episodes []models.TrackedEpisode
+	err = db.Where("tracked_show_id = ? AND season_number = ?", show.ID, seasonNumber).
+		Order("episode_number ASC").
+		Find(&episodes).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(episodes) == 0 {
+		return nil, errors.New("season not found")
+	}
+
+	season := groupSeasons(episodes)[0]
+	return &season, nil
+}
+
+// SetEpisodeWatched marks one episode of the user's show as watched or not.
+func (s *ShowService) SetEpisodeWatched(id, userID, episodeID uint, watched bool) (*models.TrackedEpisode, error) {
+	show, err := s.getShow(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB()
+	var episode models.TrackedEpisode
+	if err := db.Where("id = ? AND tracked_show_id = ?", episodeID, show.ID).First(&episode).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("episode not found")
+		}
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		episode.WatchedAt = watchedAt(watched)
+		if err := tx.Model(&episode).UpdateColumn("watched_at", episode.WatchedAt).Error; err != nil {
+			return err
+		}
+		return refreshShowStatus(tx, show)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &episode, nil
+}
+
+// SetSeasonWatched marks every aired episode of a season as watched, or
+// every episode of it as unwatched. Episodes already watched keep their
+// original date.
+func (s *ShowService) SetSeasonWatched(id, userID uint, seasonNumber int, watched bool) error {
+	show, err := s.getShow(id, userID)
+	if err != nil {
+		return err
+	}
+
+	db := database.GetDB()
+	return db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.TrackedEpisode{}).Where("tracked_show_id = ? AND season_number = ?", show.ID, seasonNumber)
+		if watched {
+			query = query.Where("watched_at IS NULL AND air_date <= ?", time.Now())
+		}
+		if err := query.UpdateColumn("watched_at", watchedAt(watched)).Error; err != nil {
+			return err
+		}
+		return refreshShowStatus(tx, show)
+	})
+}
+
+// DeleteShow removes a show and its episodes from the user's list.
+func (s *ShowService) DeleteShow(id, userID uint) error {
+	show, err := s.getShow(id, userID)
+	if err != nil {
+		return err
+	}
+
+	db := database.GetDB()
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tracked_show_id = ?", show.ID).Delete(&models.TrackedEpisode{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(show).Error
+	})
+}
+
+// GetNextEpisodes returns, for each show the user has started and not caught
+// up with, the first aired episode they have not watched. Shows watched most
+// recently come first.
+func (s *ShowService) GetNextEpisodes(userID uint, limit int) ([]models.NextEpisode, error) {
+	db := database.GetDB()
+	now := time.Now()
+
+	var progress []struct {
+		TrackedShowID uint
+		Remaining     int
+	}
+	err := db.Table("tracked_episodes e").
+		Select("e.tracked_show_id, COUNT(*) FILTER (WHERE e.watched_at IS NULL AND e.air_date <= ?) AS remaining", now).
+		Joins("JOIN tracked_shows s ON s.id = e.tracked_show_id").
+		Where("s.user_id = ?", userID).
+		Group("e.tracked_show_id").
+		Having("MAX(e.watched_at) IS NOT NULL AND COUNT(*) FILTER (WHERE e.watched_at IS NULL AND e.air_date <= ?) > 0", now).
+		Order("MAX(e.watched_at) DESC").
+		Limit(limit).
+		Scan(&progress).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(progress) == 0 {
+		return []models.NextEpisode{}, nil
+	}
+
+	showIDs := make([]uint, 0, len(progress))
+	for _, p := range progress {
+		showIDs = append(showIDs, p.TrackedShowID)
+	}
+
+	var shows []models.TrackedShow
+	if err := db.Where("id IN ?", showIDs).Find(&shows).Error; err != nil {
+		return nil, err
+	}
+	showsByID := make(map[uint]models.TrackedShow, len(shows))
+	for _, show := range shows {
+		showsByID[show.ID] = show
+	}
+
+	var episodes []models.TrackedEpisode
+	err = db.Raw(`
+		SELECT DISTINCT ON (tracked_show_id) *
+		FROM tracked_episodes
+		WHERE tracked_show_id IN ? AND watched_at IS NULL AND air_date <= ?
+		ORDER BY tracked_show_id, season_number, episode_number`, showIDs, now).Scan(&episodes).Error
+	if err != nil {
+		return nil, err
+	}
+	episodesByShow := make(map[uint]models.TrackedEpisode, len(episodes))
+	for _, episode := range episodes {
+		episodesByShow[episode.TrackedShowID] = episode
+	}
+
+	next := make([]models.NextEpisode, 0, len(progress))
+	for _, p := range progress {
+		next = append(next, models.NextEpisode{
+			Show:      showsByID[p.TrackedShowID],
+			Episode:   episodesByShow[p.TrackedShowID],
+			Remaining: p.Remaining,
+		})
+	}
+
+	return next, nil
+}
+
+// RefreshStale re-syncs up to batchSize shows that have never been synced,
+// or that are still airing and were last synced more than maxAge ago, least
+// recently tried first. Ended and canceled shows do not get new episodes and
+// are left alone. Every try is recorded, so shows TMDB keeps failing on
+// don't hold up the rest of the queue.
+func (s *ShowService) RefreshStale(batchSize int, maxAge, delay time.Duration) error {
+	db := database.GetDB()
+
+	var tmdbIDs []int
+	err := db.Model(&models.TrackedShow{}).
+		Where("synced_at IS NULL OR (synced_at < ? AND show_status NOT IN ?)", time.Now().Add(-maxAge), []string{"Ended", "Canceled"}).
+		Group("tmdb_id").
+		Order("MIN(tried_at) NULLS FIRST, MIN(synced_at) NULLS FIRST").
+		Limit(batchSize).
+		Pluck("tmdb_id", &tmdbIDs).Error
+	if err != nil {
+		return err
+	}
+
+	for i, tmdbID := range tmdbIDs {
+		if i > 0 {
+			time.Sleep(delay)
+		}
+		err := db.Model(&models.TrackedShow{}).
+			Where("tmdb_id = ?", tmdbID).
+			UpdateColumn("tried_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		if err := s.refreshShow(tmdbID, delay); err != nil {
+			log.Printf("Failed to refresh TMDB show %d: %v", tmdbID, err)
+		}
+	}
+
+	return nil
+}
+
+// refreshShow re-syncs every tracked copy of a TMDB series. The series is
+// fetched once per language its owners use, so names and overviews are
+// stored in each owner's language.
+func (s *ShowService) refreshShow(tmdbID int, delay time.Duration) error {
+	db := database.GetDB()
+
+	requests := 0
+	for _, language := range i18n.Supported {
+		var shows []models.TrackedShow
+		err := db.Where("tmdb_id = ?", tmdbID).
+			Where("user_id IN (SELECT id FROM users WHERE COALESCE(NULLIF(language, ''), ?) = ?)", i18n.Default, language.Code).
+			Find(&shows).Error
+		if err != nil {
+			return err
+		}
+		if len(shows) == 0 {
+			continue
+		}
+
+		if requests > 0 {
+			time.Sleep(delay)
+		}
+		requests++
+
+		localized := s.WithLocale(language.TMDB, "")
+		details, err := localized.tmdbService.GetShowDetails(tmdbID)
+		if err != nil {
+			return err
+		}
+		if err := localized.syncShow(shows, details, delay); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncShow updates tracked copies of a TMDB series with details, fetched in
+// the service's language, and stores new or changed episodes. A season is
+// only fetched when some copy is missing episodes of it or was never synced
+// (its owner may have changed language), or when it is the latest season
+// and may still be airing.
+func (s *ShowService) syncShow(shows []models.TrackedShow, details *models.TMDBShowDetail, delay time.Duration) error {
+	db := database.GetDB()
+
+	showIDs := make([]uint, 0, len(shows))
+	for _, show := range shows {
+		showIDs = append(showIDs, show.ID)
+	}
+
+	var stored []struct {
+		TrackedShowID uint
+		SeasonNumber  int
+		Count         int
+	}
+	err := db.Model(&models.TrackedEpisode{}).
+		Select("tracked_show_id, season_number, COUNT(*) AS count").
+		Where("tracked_show_id IN ?", showIDs).
+		Group("tracked_show_id, season_number").
+		Scan(&stored).Error
+	if err != nil {
+		return err
+	}
+	counts := make(map[uint]map[int]int)
+	for _, row := range stored {
+		if counts[row.TrackedShowID] == nil {
+			counts[row.TrackedShowID] = make(map[int]int)
+		}
+		counts[row.TrackedShowID][row.SeasonNumber] = row.Count
+	}
+
+	latest := 0
+	for _, summary := range details.Seasons {
+		if summary.SeasonNumber > latest {
+			latest = summary.SeasonNumber
+		}
+	}
+
+	requests := 0
+	for _, summary := range details.Seasons {
+		if summary.SeasonNumber == 0 {
+			continue
+		}
+
+		needed := summary.SeasonNumber == latest
+		for _, show := range shows {
+			if show.SyncedAt == nil || counts[show.ID][summary.SeasonNumber] != summary.EpisodeCount {
+				needed = true
+			}
+		}
+		if !needed {
+			continue
+		}
+
+		if requests > 0 {
+			time.Sleep(delay)
+		}
+		requests++
+
+		season, err := s.tmdbService.GetSeasonDetails(details.ID, summary.SeasonNumber)
+		if err != nil {
+			return err
+		}
+
+		for _, show := range shows {
+			if err := upsertEpisodes(db, show.ID, season.Episodes); err != nil {
+				return err
+			}
+		}
+	}
+
+	now := time.Now()
+	for i := range shows {
+		show := &shows[i]
+		applyShowDetails(show, details)
+		show.SyncedAt = &now
+
+		// UpdateColumns skips updated_at: a sync is not a user edit
+		err := db.Model(show).UpdateColumns(map[string]interface{}{
+			"name":              show.Name,
+			"original_name":     show.OriginalName,
+			"overview":          show.Overview,
+			"first_air_date":    show.FirstAirDate,
+			"poster_path":       show.PosterPath,
+			"backdrop_path":     show.BackdropPath,
+			"genre_ids":         show.GenreIDs,
+			"original_language": show.OriginalLanguage,
+			"show_status":       show.ShowStatus,
+			"episode_run_time":  show.EpisodeRunTime,
+			"vote_average":      show.VoteAverage,
+			"synced_at":         show.SyncedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// New episodes may have aired since the user caught up
+		if err := refreshShowStatus(db, show); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *ShowService) getShow(id, userID uint) (*models.TrackedShow, error) {
+	db := database.GetDB()
+	var show models.TrackedShow
+
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&show).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("show not found")
+		}
+		return nil, err
+	}
+
+	return &show, nil
+}
+
+func applyShowDetails(show *models.TrackedShow, details *models.TMDBShowDetail) {
+	genreIDs := make(models.IntArray, 0, len(details.Genres))
+	for _, genre := range details.Genres {
+		genreIDs = append(genreIDs, genre.ID)
+	}
+
+	show.Name = details.Name
+	show.OriginalName = details.OriginalName
+	show.Overview = details.Overview
+	show.FirstAirDate = parseReleaseDate(details.FirstAirDate)
+	show.PosterPath = details.PosterPath
+	show.BackdropPath = details.BackdropPath
+	show.GenreIDs = genreIDs
+	show.OriginalLanguage = details.OriginalLanguage
+	show.ShowStatus = details.Status
+	show.VoteAverage = details.VoteAverage
+	show.EpisodeRunTime = nil
+	if len(details.EpisodeRunTime) > 0 && details.EpisodeRunTime[0] > 0 {
+		show.EpisodeRunTime = &details.EpisodeRunTime[0]
+	}
+}
+
+// upsertEpisodes stores TMDB episodes for a tracked show, updating the ones
+// already stored without touching their watched state.
+func upsertEpisodes(db *gorm.DB, showID uint, tmdbEpisodes []models.TMDBEpisode) error {
+	if len(tmdbEpisodes) == 0 {
+		return nil
+	}
+
+	episodes := make([]models.TrackedEpisode, 0, len(tmdbEpisodes))
+	for _, e := range tmdbEpisodes {
+		episode := models.TrackedEpisode{
+			TrackedShowID: showID,
+			SeasonNumber:  e.SeasonNumber,
+			EpisodeNumber: e.EpisodeNumber,
+			TMDBId:        e.ID,
+			Name:          e.Name,
+			Overview:      e.Overview,
+			AirDate:       parseReleaseDate(e.AirDate),
+			StillPath:     e.StillPath,
+		}
+		if e.Runtime > 0 {
+			runtime := e.Runtime
+			episode.Runtime = &runtime
+		}
+		episodes = append(episodes, episode)
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tracked_show_id"}, {Name: "season_number"}, {Name: "episode_number"}},
+		DoUpdates: clause.AssignmentColumns([]string{"tmdb_id", "name", "overview", "air_date", "runtime", "still_path"}),
+	}).Create(&episodes).Error
+}
+
+// refreshShowStatus derives a show's status from its episodes: por_ver
+// until one is watched, vista when every aired episode is, viendo otherwise.
+func refreshShowStatus(db *gorm.DB, show *models.TrackedShow) error {
+	var counts struct {
+		Watched int
+		Aired   int
+	}
+	err := db.Model(&models.TrackedEpisode{}).
+		Select("COUNT(watched_at) AS watched, COUNT(*) FILTER (WHERE air_date <= ?) AS aired", time.Now()).
+		Where("tracked_show_id = ?", show.ID).
+		Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+
+	status := models.StatusWatching
+	switch {
+	case counts.Watched == 0:
+		status = models.StatusToBe
+	case counts.Watched >= counts.Aired:
+		status = models.StatusWatched
+	}
+
+	show.WatchedEpisodes = counts.Watched
+	show.AiredEpisodes = counts.Aired
+	if show.Status == status {
+		return nil
+	}
+	show.Status = status
+	return db.Model(show).Update("status", status).Error
+}
+
+// fillEpisodeCounts sets WatchedEpisodes and AiredEpisodes on shows.
+func fillEpisodeCounts(db *gorm.DB, shows []models.TrackedShow) error {
+	if len(shows) == 0 {
+		return nil
+	}
+
+	showIDs := make([]uint, 0, len(shows))
+	for _, show := range shows {
+		showIDs = append(showIDs, show.ID)
+	}
+
+	var counts []struct {
+		TrackedShowID uint
+		Watched       int
+		Aired         int
+	}
+	err := db.Model(&models.TrackedEpisode{}).
+		Select("tracked_show_id, COUNT(watched_at) AS watched, COUNT(*) FILTER (WHERE air_date <= ?) AS aired", time.Now()).
+		Where("tracked_show_id IN ?", showIDs).
+		Group("tracked_show_id").
+		Scan(&counts).Error
+	if err != nil {
+		return err
+	}
+
+	byShow := make(map[uint]int, len(counts))
+	for i, c := range counts {
+		byShow[c.TrackedShowID] = i
+	}
+	for i := range shows {
+		if j, ok := byShow[shows[i].ID]; ok {
+			shows[i].WatchedEpisodes = counts[j].Watched
+			shows[i].AiredEpisodes = counts[j].Aired
+		}
+	}
+
+	return nil
+}
+
+// groupSeasons splits episodes ordered by season and number into seasons.
+func groupSeasons(episodes []models.TrackedEpisode) []models.Season {
+	var seasons []models.Season
+	for _, episode := range episodes {
+		if len(seasons) == 0 || seasons[len(seasons)-1].Number != episode.SeasonNumber {
+			seasons = append(seasons, models.Season{ShowID: episode.TrackedShowID, Number: episode.SeasonNumber})
+		}
+		season := &seasons[len(seasons)-1]
+		season.Episodes = append(season.Episodes, episode)
+		if episode.WatchedAt != nil {
+			season.Watched++
+		}
+		if episode.Aired() {
+			season.Aired++
+		}
+	}
+
+	return seasons
+}
+
+// episodeStats returns how many episodes the user has watched and for how
+// many minutes, using the show's typical episode length when an episode's
+// own runtime is unknown.
+func episodeStats(db *gorm.DB, userID uint) (episodes, minutes int, err error) {
+	var stats struct {
+		Episodes int
+		Minutes  int
+	}
+	err = db.Table("tracked_episodes e").
+		Select("COUNT(*) AS episodes, COALESCE(SUM(COALESCE(e.runtime, s.episode_run_time, 0)), 0) AS minutes").
+		Joins("JOIN tracked_shows s ON s.id = e.tracked_show_id").
+		Where("s.user_id = ? AND e.watched_at IS NOT NULL", userID).
+		Scan(&stats).Error
+	return stats.Episodes, stats.Minutes, err
+}
+
+func watchedAt(watched bool) *time.Time {
+	if !watched {
+		return nil
+	}
+	now := time.Now()
+	return &now
+}
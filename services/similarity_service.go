@@ -0,0 +1,232 @@
+package services
+
+import (
+	"math"
+	"movie-tracker/database"
+	"movie-tracker/i18n"
+	"movie-tracker/models"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// similarityShrinkage damps similarities computed from few shared movies:
+	// a pair with n movies in common keeps n/(n+shrinkage) of its correlation.
+	similarityShrinkage = 5
+	// lovedRating is the minimum rating for a neighbour's movie to be suggested.
+	lovedRating = 8
+)
+
+// TasteSuggestion is a movie loved by users with similar taste.
+type TasteSuggestion struct {
+	TMDBId        int        `json:"tmdb_id"`
+	Title         string     `json:"title"`
+	PosterPath    string     `json:"poster_path"`
+	ReleaseDate   *time.Time `json:"release_date"`
+	LovedBy       int        `json:"loved_by"`
+	AverageRating float64    `json:"average_rating"`
+	Score         float64    `json:"score"`
+	Explanation   string     `json:"explanation"`
+}
+
+// SimilarityService finds users with similar taste and suggests what they
+// loved. Neighbours are computed in batch by ComputeSimilarities.
+type SimilarityService struct{}
+
+func NewSimilarityService() *SimilarityService {
+	return &SimilarityService{}
+}
+
+// userRating is one rating used to compare users.
+type userRating struct {
+	UserID uint
+	TMDBId int
+	Rating int
+}
+
+// ComputeSimilarities recomputes every user's topN most similar users. Two
+// users are compared with the Pearson correlation of the ratings they gave
+// to the same movies, and only if they rated at least minOverlap movies in
+// common. Users who opted out are left out entirely.
+func (s *SimilarityService) ComputeSimilarities(topN, minOverlap int) error {
+	db := database.GetDB()
+
+	var rows []userRating
+	err := db.Table("favorite_movies").
+		Select("favorite_movies.user_id, favorite_movies.tmdb_id, favorite_movies.rating").
+		Joins("JOIN users ON users.id = favorite_movies.user_id").
+		Where("favorite_movies.rating IS NOT NULL AND favorite_movies.deleted_at IS NULL").
+		Where("users.deleted_at IS NULL AND NOT users.taste_matching_opt_out").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	ratings := make(map[uint]map[int]int)
+	for _, row := range rows {
+		if ratings[row.UserID] == nil {
+			ratings[row.UserID] = make(map[int]int)
+		}
+		ratings[row.UserID][row.TMDBId] = row.Rating
+	}
+
+	userIDs := make([]uint, 0, len(ratings))
+	for userID := range ratings {
+		userIDs = append(userIDs, userID)
+	}
+
+	now := time.Now()
+	neighbours := make(map[uint][]models.UserSimilarity)
+	for i := 0; i < len(userIDs); i++ {
+		for j := i + 1; j < len(userIDs); j++ {
+			a, b := userIDs[i], userIDs[j]
+			similarity, overlap := tasteSimilarity(ratings[a], ratings[b])
+			if overlap < minOverlap || similarity <= 0 {
+				continue
+			}
+
+			neighbours[a] = append(neighbours[a], models.UserSimilarity{UserID: a, NeighborID: b, Similarity: similarity, Overlap: overlap, ComputedAt: now})
+			neighbours[b] = append(neighbours[b], models.UserSimilarity{UserID: b, NeighborID: a, Similarity: similarity, Overlap: overlap, ComputedAt: now})
+		}
+	}
+
+	var similarities []models.UserSimilarity
+	for _, list := range neighbours {
+		sort.Slice(list, func(i, j int) bool { return list[i].Similarity > list[j].Similarity })
+		if len(list) > topN {
+			list = list[:topN]
+		}
+		similarities = append(similarities, list...)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.UserSimilarity{}).Error; err != nil {
+			return err
+		}
+		if len(similarities) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(similarities, 500).Error
+	})
+}
+
+// GetSuggestions returns up to limit movies that the user's neighbours
+// rated lovedRating or more and the user has not tracked, best first, each
+// explained in lang.
+func (s *SimilarityService) GetSuggestions(userID uint, limit int, lang string) ([]TasteSuggestion, error) {
+	db := database.GetDB()
+
+	var neighbours []models.UserSimilarity
+	if err := db.Where("user_id = ?", userID).Find(&neighbours).Error; err != nil {
+		return nil, err
+	}
+	if len(neighbours) == 0 {
+		return []TasteSuggestion{}, nil
+	}
+
+	similarity := make(map[uint]float64, len(neighbours))
+	neighbourIDs := make([]uint, 0, len(neighbours))
+	for _, n := range neighbours {
+		similarity[n.NeighborID] = n.Similarity
+		neighbourIDs = append(neighbourIDs, n.NeighborID)
+	}
+
+	// The subquery is unscoped on purpose: movies in the user's trash are
+	// not suggested either
+	var loved []models.FavoriteMovie
+	err := db.Where("user_id IN ? AND rating >= ?", neighbourIDs, lovedRating).
+		Where("tmdb_id NOT IN (SELECT tmdb_id FROM favorite_movies WHERE user_id = ?)", userID).
+		Find(&loved).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byMovie := make(map[int]*TasteSuggestion)
+	var suggestions []*TasteSuggestion
+	for _, favorite := range loved {
+		suggestion, ok := byMovie[favorite.TMDBId]
+		if !ok {
+			suggestion = &TasteSuggestion{
+				TMDBId:      favorite.TMDBId,
+				Title:       favorite.Title,
+				PosterPath:  favorite.PosterPath,
+				ReleaseDate: favorite.ReleaseDate,
+			}
+			byMovie[favorite.TMDBId] = suggestion
+			suggestions = append(suggestions, suggestion)
+		}
+		suggestion.LovedBy++
+		suggestion.AverageRating += float64(*favorite.Rating)
+		suggestion.Score += similarity[favorite.UserID] * ratingWeight(*favorite.Rating)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].Score > suggestions[j].Score })
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	result := make([]TasteSuggestion, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		suggestion.AverageRating /= float64(suggestion.LovedBy)
+		if suggestion.LovedBy == 1 {
+			suggestion.Explanation = i18n.T(lang, "Loved by someone with similar taste (%.0f/10)", suggestion.AverageRating)
+		} else {
+			suggestion.Explanation = i18n.T(lang, "Loved by %d people with similar taste (avg %.1f/10)", suggestion.LovedBy, suggestion.AverageRating)
+		}
+		result = append(result, *suggestion)
+	}
+
+	return result, nil
+}
+
+// RemoveUser deletes a user's neighbours and removes them from everyone
+// else's, so an opt-out takes effect before the next batch run.
+func (s *SimilarityService) RemoveUser(tx *gorm.DB, userID uint) error {
+	return tx.Where("user_id = ? OR neighbor_id = ?", userID, userID).Delete(&models.UserSimilarity{}).Error
+}
+
+// tasteSimilarity is the Pearson correlation of two users' ratings, shrunk
+// by similarityShrinkage, and how many movies they both rated.
+func tasteSimilarity(a, b map[int]int) (float64, int) {
+	correlation, overlap := pearson(a, b)
+	return correlation * float64(overlap) / float64(overlap+similarityShrinkage), overlap
+}
+
+// pearson returns the correlation between two users' ratings of the movies
+// they both rated, and how many movies that is. Users who gave every shared
+// movie the same rating have no defined correlation and score 0.
+func pearson(a, b map[int]int) (float64, int) {
+	var shared []int
+	for tmdbID := range a {
+		if _, ok := b[tmdbID]; ok {
+			shared = append(shared, tmdbID)
+		}
+	}
+	if len(shared) < 2 {
+		return 0, len(shared)
+	}
+
+	var meanA, meanB float64
+	for _, tmdbID := range shared {
+		meanA += float64(a[tmdbID])
+		meanB += float64(b[tmdbID])
+	}
+	meanA /= float64(len(shared))
+	meanB /= float64(len(shared))
+
+	var covariance, varianceA, varianceB float64
+	for _, tmdbID := range shared {
+		da := float64(a[tmdbID]) - meanA
+		db := float64(b[tmdbID]) - meanB
+		covariance += da * db
+		varianceA += da * da
+		varianceB += db * db
+	}
+	if varianceA == 0 || varianceB == 0 {
+		return 0, len(shared)
+	}
+
+	return covariance / math.Sqrt(varianceA*varianceB), len(shared)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTasteSimilarity(t *testing.T) {
+	tests := []struct {
+		name           string
+		a, b           map[int]int
+		wantPearson    float64
+		wantSimilarity float64
+		wantOverlap    int
+	}{
+		{
+			name:           "same ratings",
+			a:              map[int]int{1: 8, 2: 6, 3: 9, 4: 4, 5: 7},
+			b:              map[int]int{1: 8, 2: 6, 3: 9, 4: 4, 5: 7},
+			wantPearson:    1,
+			wantSimilarity: 0.5,
+			wantOverlap:    5,
+		},
+		{
+			name:           "same taste, harsher critic",
+			a:              map[int]int{1: 8, 2: 6, 3: 9, 4: 4, 5: 7},
+			b:              map[int]int{1: 7, 2: 5, 3: 8, 4: 3, 5: 6},
+			wantPearson:    1,
+			wantSimilarity: 0.5,
+			wantOverlap:    5,
+		},
+		{
+			name:           "opposite ratings",
+			a:              map[int]int{1: 8, 2: 6, 3: 9, 4: 4, 5: 7},
+			b:              map[int]int{1: 3, 2: 5, 3: 2, 4: 7, 5: 4},
+			wantPearson:    -1,
+			wantSimilarity: -0.5,
+			wantOverlap:    5,
+		},
+		{
+			name:           "partial agreement",
+			a:              map[int]int{1: 1, 2: 2, 3: 3},
+			b:              map[int]int{1: 1, 2: 3, 3: 2},
+			wantPearson:    0.5,
+			wantSimilarity: 0.5 * 3 / 8,
+			wantOverlap:    3,
+		},
+		{
+			name:           "only shared movies count",
+			a:              map[int]int{1: 1, 2: 2, 3: 3, 4: 10},
+			b:              map[int]int{1: 1, 2: 3, 3: 2, 5: 1},
+			wantPearson:    0.5,
+			wantSimilarity: 0.5 * 3 / 8,
+			wantOverlap:    3,
+		},
+		{
+			name:        "same rating for everything",
+			a:           map[int]int{1: 7, 2: 7, 3: 7},
+			b:           map[int]int{1: 2, 2: 9, 3: 5},
+			wantOverlap: 3,
+		},
+		{
+			name:        "one movie in common",
+			a:           map[int]int{1: 8, 2: 3},
+			b:           map[int]int{1: 9, 3: 4},
+			wantOverlap: 1,
+		},
+		{
+			name: "nothing in common",
+			a:    map[int]int{1: 8},
+			b:    map[int]int{2: 8},
+		},
+		{
+			name: "no ratings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			correlation, overlap := pearson(tt.a, tt.b)
+			if overlap != tt.wantOverlap {
+				t.Errorf("pearson() overlap = %d, want %d", overlap, tt.wantOverlap)
+			}
+			if math.Abs(correlation-tt.wantPearson) > 1e-9 {
+				t.Errorf("pearson() = %v, want %v", correlation, tt.wantPearson)
+			}
+
+			similarity, overlap := tasteSimilarity(tt.a, tt.b)
+			if overlap != tt.wantOverlap {
+				t.Errorf("tasteSimilarity() overlap = %d, want %d", overlap, tt.wantOverlap)
+			}
+			if math.Abs(similarity-tt.wantSimilarity) > 1e-9 {
+				t.Errorf("tasteSimilarity() = %v, want %v", similarity, tt.wantSimilarity)
+			}
+
+			// The comparison doesn't depend on which user comes first
+			if swapped, _ := tasteSimilarity(tt.b, tt.a); math.Abs(swapped-similarity) > 1e-9 {
+				t.Errorf("tasteSimilarity() swapped = %v, want %v", swapped, similarity)
+			}
+		})
+	}
+}
+
+// More movies in common means less shrinkage of the same correlation.
+func TestTasteSimilarityGrowsWithOverlap(t *testing.T) {
+	a := make(map[int]int)
+	b := make(map[int]int)
+	previous := 0.0
+	for tmdbID := 1; tmdbID <= 20; tmdbID++ {
+		a[tmdbID] = tmdbID%10 + 1
+		b[tmdbID] = tmdbID%10 + 1
+		if tmdbID < 2 {
+			continue
+		}
+
+		similarity, _ := tasteSimilarity(a, b)
+		if similarity <= previous || similarity >= 1 {
+			t.Fatalf("tasteSimilarity() with %d movies = %v, want between %v and 1", tmdbID, similarity, previous)
+		}
+		previous = similarity
+	}
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"movie-tracker/models"
+	"time"
+)
+
+// TransitionError is returned when a status change is not allowed.
+type TransitionError struct {
+	message string
+}
+
+func (e *TransitionError) Error() string {
+	return e.message
+}
+
+// applyTransition checks that favorite may move to status according to
+// models.StatusDefinitions and adds the side effects of leaving the current
+// status and entering the new one to updates. Keeping the current status is
+// always allowed and has no effects.
+func applyTransition(favorite *models.FavoriteMovie, status models.Status, updates map[string]interface{}) error {
+	def, ok := models.GetStatusDefinition(status)
+	if !ok {
+		return &TransitionError{message: "invalid status"}
+	}
+
+	if status == favorite.Status {
+		return nil
+	}
+
+	if !def.HasReason {
+		delete(updates, "abandon_reason")
+	}
+
+	var effects []models.StatusEffect
+	if from, ok := models.GetStatusDefinition(favorite.Status); ok {
+		if !from.CanTransitionTo(status) {
+			return &TransitionError{message: fmt.Sprintf("cannot change status from %s to %s", from.Label, def.Label)}
+		}
+		effects = append(effects, from.OnLeave...)
+	}
+	effects = append(effects, def.OnEnter...)
+
+	for _, effect := range effects {
+		switch effect {
+		case models.EffectSetWatchedAt:
+			if favorite.WatchedAt == nil {
+				updates["watched_at"] = time.Now()
+			}
+		case models.EffectClearWatchedAt:
+			if favorite.WatchedAt != nil {
+				updates["watched_at"] = nil
+			}
+		case models.EffectStartProgress:
+			if _, set := updates["progress_minutes"]; !set && favorite.ProgressMinutes == nil {
+				updates["progress_minutes"] = 0
+			}
+		case models.EffectClearProgress:
+			if favorite.ProgressMinutes != nil {
+				updates["progress_minutes"] = nil
+			}
+		case models.EffectClearReason:
+			if favorite.AbandonReason != "" {
+				updates["abandon_reason"] = ""
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyInitialStatus applies the side effects of a status to a favorite that
+// is about to be created.
+func applyInitialStatus(favorite *models.FavoriteMovie) error {
+	def, ok := models.GetStatusDefinition(favorite.Status)
+	if !ok {
+		return errors.New("invalid status")
+	}
+
+	if def.HasEffect(models.EffectSetWatchedAt) && favorite.WatchedAt == nil {
+		now := time.Now()
+		favorite.WatchedAt = &now
+	}
+	if def.HasEffect(models.EffectStartProgress) && favorite.ProgressMinutes == nil {
+		zero := 0
+		favorite.ProgressMinutes = &zero
+	}
+
+	return nil
+}
+
+// NeedsRatingPrompt reports whether the user should be asked to rate a
+// favorite after it moved into its current status.
+func NeedsRatingPrompt(favorite *models.FavoriteMovie) bool {
+	def, ok := models.GetStatusDefinition(favorite.Status)
+	return ok && def.HasEffect(models.EffectPromptRating) && favorite.Rating == nil
+}
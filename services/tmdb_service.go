@@ -7,74 +7,99 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 )
 
 type TMDBService struct {
 	apiKey  string
 	baseURL string
+	client  *http.Client
+	// language and region are sent with every request when set, so titles,
+	// overviews and release dates come back localized
+	language string
+	region   string
 }
 
 func NewTMDBService() *TMDBService {
 	return &TMDBService{
 		apiKey:  os.Getenv("TMDB_API_KEY"),
 		baseURL: "https://api.themoviedb.org/3",
+		client:  &http.Client{Timeout: 10 * time.Second},
 	}
 }
 
-func (s *TMDBService) SearchMovies(query string, page int) (*models.TMDBResponse, error) {
-	if page <= 0 {
-		page = 1
-	}
+// WithLocale returns a copy of the service that requests content in
+// language (e.g. "es-MX") and region (e.g. "MX"). Empty values keep TMDB's
+// defaults.
+func (s *TMDBService) WithLocale(language, region string) *TMDBService {
+	localized := *s
+	localized.language = language
+	localized.region = region
+	return &localized
+}
 
-	endpoint := fmt.Sprintf("%s/search/movie", s.baseURL)
-	params := url.Values{
-		"api_key": {s.apiKey},
-		"query":   {query},
-		"page":    {fmt.Sprintf("%d", page)},
+// get performs a GET request against a TMDB endpoint and decodes the JSON
+// response into out. The API key and the service's locale are added to
+// params automatically; a language already in params wins.
+func (s *TMDBService) get(path string, params url.Values, out interface{}) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("api_key", s.apiKey)
+	if s.language != "" && params.Get("language") == "" {
+		params.Set("language", s.language)
+	}
+	if s.region != "" && params.Get("region") == "" {
+		params.Set("region", s.region)
 	}
 
-	resp, err := http.Get(endpoint + "?" + params.Encode())
+	resp, err := s.client.Get(s.baseURL + path + "?" + params.Encode())
 	if err != nil {
-		return nil, fmt.Errorf("error making request to TMDB: %w", err)
+		return fmt.Errorf("error making request to TMDB: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API returned status code: %d", resp.StatusCode)
+		return fmt.Errorf("TMDB API returned status code: %d", resp.StatusCode)
 	}
 
-	var tmdbResponse models.TMDBResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tmdbResponse); err != nil {
-		return nil, fmt.Errorf("error decoding TMDB response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding TMDB response: %w", err)
 	}
 
-	return &tmdbResponse, nil
+	return nil
 }
 
-func (s *TMDBService) GetPopularMovies(page int) (*models.TMDBResponse, error) {
+func (s *TMDBService) SearchMovies(query string, page int) (*models.TMDBResponse, error) {
 	if page <= 0 {
 		page = 1
 	}
 
-	endpoint := fmt.Sprintf("%s/movie/popular", s.baseURL)
 	params := url.Values{
-		"api_key": {s.apiKey},
-		"page":    {fmt.Sprintf("%d", page)},
+		"query": {query},
+		"page":  {fmt.Sprintf("%d", page)},
 	}
 
-	resp, err := http.Get(endpoint + "?" + params.Encode())
-	if err != nil {
-		return nil, fmt.Errorf("error making request to TMDB: %w", err)
+	var tmdbResponse models.TMDBResponse
+	if err := s.get("/search/movie", params, &tmdbResponse); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API returned status code: %d", resp.StatusCode)
+	return &tmdbResponse, nil
+}
+
+func (s *TMDBService) GetPopularMovies(page int) (*models.TMDBResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	params := url.Values{
+		"page": {fmt.Sprintf("%d", page)},
 	}
 
 	var tmdbResponse models.TMDBResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tmdbResponse); err != nil {
-		return nil, fmt.Errorf("error decoding TMDB response: %w", err)
+	if err := s.get("/movie/popular", params, &tmdbResponse); err != nil {
+		return nil, err
 	}
 
 	return &tmdbResponse, nil
@@ -85,74 +110,227 @@ func (s *TMDBService) GetTrendingMovies(page int) (*models.TMDBResponse, error)
 		page = 1
 	}
 
-	endpoint := fmt.Sprintf("%s/trending/movie/week", s.baseURL)
 	params := url.Values{
-		"api_key": {s.apiKey},
-		"page":    {fmt.Sprintf("%d", page)},
+		"page": {fmt.Sprintf("%d", page)},
 	}
 
-	resp, err := http.Get(endpoint + "?" + params.Encode())
-	if err != nil {
-		return nil, fmt.Errorf("error making request to TMDB: %w", err)
+	var tmdbResponse models.TMDBResponse
+	if err := s.get("/trending/movie/week", params, &tmdbResponse); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API returned status code: %d", resp.StatusCode)
+	return &tmdbResponse, nil
+}
+
+// DiscoverMovies browses TMDB's catalogue with filter instead of a text
+// query. Pages past DiscoverMaxPage are not served by TMDB.
+func (s *TMDBService) DiscoverMovies(filter DiscoverFilter, page int) (*models.TMDBResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if page > DiscoverMaxPage {
+		page = DiscoverMaxPage
 	}
 
+	params := filter.tmdbParams()
+	params.Set("page", fmt.Sprintf("%d", page))
+
 	var tmdbResponse models.TMDBResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tmdbResponse); err != nil {
-		return nil, fmt.Errorf("error decoding TMDB response: %w", err)
+	if err := s.get("/discover/movie", params, &tmdbResponse); err != nil {
+		return nil, err
 	}
 
 	return &tmdbResponse, nil
 }
 
-func (s *TMDBService) GetMovieDetails(movieID int) (*models.TMDBMovie, error) {
-	endpoint := fmt.Sprintf("%s/movie/%d", s.baseURL, movieID)
+// GetSimilarMovies returns movies TMDB considers similar to movieID, based on
+// shared genres and keywords.
+func (s *TMDBService) GetSimilarMovies(movieID, page int) (*models.TMDBResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+
 	params := url.Values{
-		"api_key": {s.apiKey},
+		"page": {fmt.Sprintf("%d", page)},
 	}
 
-	resp, err := http.Get(endpoint + "?" + params.Encode())
-	if err != nil {
-		return nil, fmt.Errorf("error making request to TMDB: %w", err)
+	var tmdbResponse models.TMDBResponse
+	if err := s.get(fmt.Sprintf("/movie/%d/similar", movieID), params, &tmdbResponse); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API returned status code: %d", resp.StatusCode)
+	return &tmdbResponse, nil
+}
+
+// GetMovieRecommendations returns TMDB's recommendations for people who
+// liked movieID, based on what other TMDB users watched.
+func (s *TMDBService) GetMovieRecommendations(movieID, page int) (*models.TMDBResponse, error) {
+	if page <= 0 {
+		page = 1
 	}
 
+	params := url.Values{
+		"page": {fmt.Sprintf("%d", page)},
+	}
+
+	var tmdbResponse models.TMDBResponse
+	if err := s.get(fmt.Sprintf("/movie/%d/recommendations", movieID), params, &tmdbResponse); err != nil {
+		return nil, err
+	}
+
+	return &tmdbResponse, nil
+}
+
+func (s *TMDBService) GetMovieDetails(movieID int) (*models.TMDBMovie, error) {
 	var movie models.TMDBMovie
-	if err := json.NewDecoder(resp.Body).Decode(&movie); err != nil {
-		return nil, fmt.Errorf("error decoding TMDB response: %w", err)
+	if err := s.get(fmt.Sprintf("/movie/%d", movieID), nil, &movie); err != nil {
+		return nil, err
 	}
 
 	return &movie, nil
 }
 
+// GetMovieFullDetails returns a movie's details together with its cast and
+// crew, fetched in the same request with append_to_response.
 func (s *TMDBService) GetMovieFullDetails(movieID int) (*models.TMDBMovieDetail, error) {
-	endpoint := fmt.Sprintf("%s/movie/%d", s.baseURL, movieID)
 	params := url.Values{
-		"api_key": {s.apiKey},
+		"append_to_response": {"credits"},
 	}
 
-	resp, err := http.Get(endpoint + "?" + params.Encode())
-	if err != nil {
-		return nil, fmt.Errorf("error making request to TMDB: %w", err)
+	var movieDetail models.TMDBMovieDetail
+	if err := s.get(fmt.Sprintf("/movie/%d", movieID), params, &movieDetail); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("TMDB API returned status code: %d", resp.StatusCode)
+	return &movieDetail, nil
+}
+
+// GetMovieWithTranslations returns a movie's details with its credits and
+// its title and overview in every language TMDB has.
+func (s *TMDBService) GetMovieWithTranslations(movieID int) (*models.TMDBMovieDetail, error) {
+	params := url.Values{
+		"append_to_response": {"credits,translations"},
 	}
 
 	var movieDetail models.TMDBMovieDetail
-	if err := json.NewDecoder(resp.Body).Decode(&movieDetail); err != nil {
-		return nil, fmt.Errorf("error decoding TMDB response: %w", err)
+	if err := s.get(fmt.Sprintf("/movie/%d", movieID), params, &movieDetail); err != nil {
+		return nil, err
 	}
 
 	return &movieDetail, nil
-}
\ No newline at end of file
+}
+
+// GetPersonDetails returns an actor's or crew member's biography and the
+// movies they are credited in.
+func (s *TMDBService) GetPersonDetails(personID int) (*models.TMDBPerson, error) {
+	params := url.Values{
+		"append_to_response": {"movie_credits"},
+	}
+
+	var person models.TMDBPerson
+	if err := s.get(fmt.Sprintf("/person/%d", personID), params, &person); err != nil {
+		return nil, err
+	}
+
+	return &person, nil
+}
+
+// GetWatchProviders returns where a movie can be streamed, rented or bought
+// in every region TMDB has data for.
+func (s *TMDBService) GetWatchProviders(movieID int) (*models.TMDBWatchProvidersResponse, error) {
+	var providers models.TMDBWatchProvidersResponse
+	if err := s.get(fmt.Sprintf("/movie/%d/watch/providers", movieID), nil, &providers); err != nil {
+		return nil, err
+	}
+
+	return &providers, nil
+}
+
+// GetReleaseDates returns a movie's theatrical, digital and physical release
+// dates in every region TMDB has data for.
+func (s *TMDBService) GetReleaseDates(movieID int) (*models.TMDBReleaseDatesResponse, error) {
+	var releases models.TMDBReleaseDatesResponse
+	if err := s.get(fmt.Sprintf("/movie/%d/release_dates", movieID), nil, &releases); err != nil {
+		return nil, err
+	}
+
+	return &releases, nil
+}
+
+// GetProviders returns the movie watch providers available in region.
+func (s *TMDBService) GetProviders(region string) ([]models.TMDBWatchProvider, error) {
+	params := url.Values{
+		"watch_region": {region},
+	}
+
+	var providerList models.TMDBProviderListResponse
+	if err := s.get("/watch/providers/movie", params, &providerList); err != nil {
+		return nil, err
+	}
+
+	return providerList.Results, nil
+}
+
+// GetProviderRegions returns the regions TMDB has watch provider data for.
+func (s *TMDBService) GetProviderRegions() ([]models.TMDBRegion, error) {
+	var regionList models.TMDBRegionListResponse
+	if err := s.get("/watch/providers/regions", nil, &regionList); err != nil {
+		return nil, err
+	}
+
+	return regionList.Results, nil
+}
+
+// SearchShows searches TMDB for TV series by name.
+func (s *TMDBService) SearchShows(query string, page int) (*models.TMDBShowResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+
+	params := url.Values{
+		"query": {query},
+		"page":  {fmt.Sprintf("%d", page)},
+	}
+
+	var tmdbResponse models.TMDBShowResponse
+	if err := s.get("/search/tv", params, &tmdbResponse); err != nil {
+		return nil, err
+	}
+
+	return &tmdbResponse, nil
+}
+
+// GetShowDetails returns a TV series and the list of its seasons.
+func (s *TMDBService) GetShowDetails(showID int) (*models.TMDBShowDetail, error) {
+	var show models.TMDBShowDetail
+	if err := s.get(fmt.Sprintf("/tv/%d", showID), nil, &show); err != nil {
+		return nil, err
+	}
+
+	return &show, nil
+}
+
+// GetSeasonDetails returns one season of a TV series with its episodes.
+func (s *TMDBService) GetSeasonDetails(showID, seasonNumber int) (*models.TMDBSeason, error) {
+	var season models.TMDBSeason
+	if err := s.get(fmt.Sprintf("/tv/%d/season/%d", showID, seasonNumber), nil, &season); err != nil {
+		return nil, err
+	}
+
+	return &season, nil
+}
+
+// GetGenres returns TMDB's official movie genre list localized to language
+// (e.g. "es-MX", "en-US").
+func (s *TMDBService) GetGenres(language string) ([]models.Genre, error) {
+	params := url.Values{
+		"language": {language},
+	}
+
+	var genreList models.TMDBGenreListResponse
+	if err := s.get("/genre/movie/list", params, &genreList); err != nil {
+		return nil, err
+	}
+
+	return genreList.Genres, nil
+}
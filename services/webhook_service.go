@@ -0,0 +1,430 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"movie-tracker/database"
+	"movie-tracker/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxWebhooksPerUser caps how many webhooks a user can register.
+	MaxWebhooksPerUser = 10
+	// webhookMaxAttempts is how many times a delivery is tried before it is
+	// marked failed. Retries back off exponentially from webhookRetryDelay,
+	// so the last one happens about 16 minutes after the event.
+	webhookMaxAttempts = 6
+	webhookRetryDelay  = 30 * time.Second
+	// webhookLogRetention is how long finished deliveries are kept in the log.
+	webhookLogRetention = 30 * 24 * time.Hour
+	// webhookResponseLimit is how much of a response body is kept in the log.
+	webhookResponseLimit = 1024
+	// webhookRetryBatch caps the deliveries DeliverDue picks up per run.
+	webhookRetryBatch = 500
+)
+
+// webhookQueue makes delivery attempts in the background with a fixed
+// number of workers, however many events a bulk update publishes.
+// Deliveries that don't fit stay pending for DeliverDue.
+var webhookQueue = newWorkQueue("webhooks", 8, 1000)
+
+// WebhookEventTest is the event sent by the "send test event" button.
+const WebhookEventTest = "webhook.test"
+
+// WebhookEvents are the events sent to webhooks.
+var WebhookEvents = []EventType{EventFavoriteAdded, EventFavoriteStatusChanged, EventFavoriteRated, EventFavoriteDeleted, EventFavoriteRestored}
+
+// WebhookFavorite is the part of a favorite sent in webhook payloads.
+type WebhookFavorite struct {
+	ID            uint          `json:"id"`
+	TMDBId        int           `json:"tmdb_id"`
+	Title         string        `json:"title"`
+	ReleaseDate   *time.Time    `json:"release_date"`
+	PosterPath    string        `json:"poster_path"`
+	Status        models.Status `json:"status"`
+	Rating        *int          `json:"rating"`
+	RecommendedBy string        `json:"recommended_by"`
+	AddedAt       time.Time     `json:"added_at"`
+	WatchedAt     *time.Time    `json:"watched_at"`
+}
+
+// WebhookPayload is the JSON body POSTed to webhooks.
+type WebhookPayload struct {
+	Event      string              `json:"event"`
+	OccurredAt time.Time           `json:"occurred_at"`
+	Favorite   WebhookFavorite     `json:"favorite"`
+	FromStatus models.Status       `json:"from_status,omitempty"`
+	ToStatus   models.Status       `json:"to_status,omitempty"`
+	Source     models.ChangeSource `json:"source"`
+}
+
+// WebhookService manages users' webhooks and delivers favorites events to
+// them. Each request carries an X-Movie-Tracker-Signature header with the
+// hex HMAC-SHA256 of the body, keyed with the webhook's secret. Webhooks may
+// only point to public addresses, unless WEBHOOK_ALLOW_PRIVATE is set.
+type WebhookService struct {
+	client *http.Client
+}
+
+func NewWebhookService() *WebhookService {
+	return &WebhookService{
+		client: newOutboundClient(),
+	}
+}
+
+// Subscribe starts queueing a delivery to the user's webhooks for every
+// favorites event.
+func (s *WebhookService) Subscribe() {
+	Subscribe(s.handleEvent, WebhookEvents...)
+}
+
+// GetWebhooks returns the user's webhooks, oldest first.
+func (s *WebhookService) GetWebhooks(userID uint) ([]models.Webhook, error) {
+	db := database.GetDB()
+	var webhooks []models.Webhook
+
+	if err := db.Where("user_id = ?", userID).Order("created_at ASC, id ASC").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhook returns one of the user's webhooks.
+func (s *WebhookService) GetWebhook(id, userID uint) (*models.Webhook, error) {
+	db := database.GetDB()
+	var webhook models.Webhook
+
+	if err := db.Where("id = ? AND user_id = ?", id, userID).First(&webhook).Error; err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// CreateWebhook registers rawURL for the user with a new random secret.
+func (s *WebhookService) CreateWebhook(userID uint, rawURL string) (*models.Webhook, error) {
+	if err := validateOutboundURL(rawURL); err != nil {
+		return nil, err
+	}
+	if len(rawURL) > 255 {
+		return nil, errors.New("webhook URL is too long")
+	}
+
+	db := database.GetDB()
+	var count int64
+	if err := db.Model(&models.Webhook{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= MaxWebhooksPerUser {
+		return nil, errors.New("you can register at most " + strconv.Itoa(MaxWebhooksPerUser) + " webhooks")
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &models.Webhook{
+		UserID: userID,
+		URL:    rawURL,
+		Secret: secret,
+	}
+	if err := db.Create(webhook).Error; err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// DeleteWebhook removes one of the user's webhooks along with its delivery
+// log.
+func (s *WebhookService) DeleteWebhook(id, userID uint) error {
+	webhook, err := s.GetWebhook(id, userID)
+	if err != nil {
+		return err
+	}
+
+	db := database.GetDB()
+	return db.Delete(webhook).Error
+}
+
+// GetDeliveries returns the most recent deliveries of one of the user's
+// webhooks, newest first.
+func (s *WebhookService) GetDeliveries(webhookID, userID uint, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := s.GetWebhook(webhookID, userID); err != nil {
+		return nil, err
+	}
+
+	db := database.GetDB()
+	var deliveries []models.WebhookDelivery
+
+	err := db.Where("webhook_id = ?", webhookID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// SendTest sends a sample event to one of the user's webhooks right away and
+// returns the delivery. Test deliveries are not retried.
+func (s *WebhookService) SendTest(webhookID, userID uint) (*models.WebhookDelivery, error) {
+	webhook, err := s.GetWebhook(webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(WebhookPayload{
+		Event:      WebhookEventTest,
+		OccurredAt: now,
+		Favorite: WebhookFavorite{
+			TMDBId:  603,
+			Title:   "The Matrix",
+			Status:  models.StatusToBe,
+			AddedAt: now,
+		},
+		ToStatus: models.StatusToBe,
+		Source:   models.SourceWeb,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		Event:     WebhookEventTest,
+		Payload:   string(payload),
+		Status:    models.DeliveryPending,
+	}
+	db := database.GetDB()
+	if err := db.Create(delivery).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.attempt(webhook, delivery, false); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// DeliverDue queues the pending deliveries whose next attempt is due, up to
+// webhookRetryBatch per run, and removes finished deliveries older than the
+// log retention.
+func (s *WebhookService) DeliverDue() error {
+	db := database.GetDB()
+	now := time.Now()
+
+	var deliveries []models.WebhookDelivery
+	err := db.Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, now).
+		Order("next_attempt_at ASC").
+		Limit(webhookRetryBatch).
+		Find(&deliveries).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+
+		// Push the next attempt back before queueing, so a slow receiver is
+		// not sent the same delivery again by the next run
+		claimed := db.Model(delivery).
+			Where("next_attempt_at <= ?", now).
+			Update("next_attempt_at", time.Now().Add(webhookRetryDelay))
+		if claimed.Error != nil {
+			return claimed.Error
+		}
+		if claimed.RowsAffected == 0 {
+			continue
+		}
+
+		var webhook models.Webhook
+		if err := db.First(&webhook, delivery.WebhookID).Error; err != nil {
+			log.Printf("Failed to load webhook %d for delivery %d: %v", delivery.WebhookID, delivery.ID, err)
+			continue
+		}
+
+		// A full queue leaves the rest for a later run
+		if !s.queueAttempt(&webhook, delivery) {
+			break
+		}
+	}
+
+	return db.Where("status <> ? AND created_at < ?", models.DeliveryPending, now.Add(-webhookLogRetention)).
+		Delete(&models.WebhookDelivery{}).Error
+}
+
+// handleEvent stores a delivery of event to each of the user's webhooks and
+// queues the first attempts.
+func (s *WebhookService) handleEvent(event Event) {
+	webhooks, err := s.GetWebhooks(event.UserID)
+	if err != nil {
+		log.Printf("Failed to load webhooks of user %d: %v", event.UserID, err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	favorite := event.Favorite
+	payload, err := json.Marshal(WebhookPayload{
+		Event:      string(event.Type),
+		OccurredAt: event.OccurredAt,
+		Favorite: WebhookFavorite{
+			ID:            favorite.ID,
+			TMDBId:        favorite.TMDBId,
+			Title:         favorite.Title,
+			ReleaseDate:   favorite.ReleaseDate,
+			PosterPath:    favorite.PosterPath,
+			Status:        favorite.Status,
+			Rating:        favorite.Rating,
+			RecommendedBy: favorite.RecommendedBy,
+			AddedAt:       favorite.AddedAt,
+			WatchedAt:     favorite.WatchedAt,
+		},
+		FromStatus: event.FromStatus,
+		ToStatus:   event.ToStatus,
+		Source:     event.Source,
+	})
+	if err != nil {
+		log.Printf("Failed to encode %s webhook payload: %v", event.Type, err)
+		return
+	}
+
+	// The first attempt is queued right away; the retry job only picks the
+	// delivery up if that attempt never finishes or doesn't fit the queue
+	nextAttempt := time.Now().Add(webhookRetryDelay)
+	deliveries := make([]models.WebhookDelivery, len(webhooks))
+	for i, webhook := range webhooks {
+		deliveries[i] = models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			Event:         string(event.Type),
+			Payload:       string(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: &nextAttempt,
+		}
+	}
+
+	db := database.GetDB()
+	if err := db.Create(&deliveries).Error; err != nil {
+		log.Printf("Failed to queue %s webhook deliveries for user %d: %v", event.Type, event.UserID, err)
+		return
+	}
+
+	for i := range deliveries {
+		if !s.queueAttempt(&webhooks[i], &deliveries[i]) {
+			return
+		}
+	}
+}
+
+// queueAttempt queues an attempt of delivery on webhookQueue. It reports
+// false when the queue is full.
+func (s *WebhookService) queueAttempt(webhook *models.Webhook, delivery *models.WebhookDelivery) bool {
+	return webhookQueue.enqueue(func() {
+		if err := s.attempt(webhook, delivery, true); err != nil {
+			log.Printf("Failed to record webhook delivery %d: %v", delivery.ID, err)
+		}
+	})
+}
+
+// attempt POSTs delivery to webhook and records the outcome. A failed
+// attempt is scheduled for a retry when retry is set and attempts remain,
+// and marks the delivery failed otherwise. The returned error is about
+// recording the outcome, not the delivery itself.
+func (s *WebhookService) attempt(webhook *models.Webhook, delivery *models.WebhookDelivery, retry bool) error {
+	body := []byte(delivery.Payload)
+	mac := hmac.New(sha256.New, []byte(webhook.Secret))
+	mac.Write(body)
+
+	started := time.Now()
+	statusCode, responseBody, err := s.post(webhook.URL, body, map[string]string{
+		"Content-Type":              "application/json",
+		"User-Agent":                "movie-tracker-webhooks",
+		"X-Movie-Tracker-Event":     delivery.Event,
+		"X-Movie-Tracker-Delivery":  strconv.FormatUint(uint64(delivery.ID), 10),
+		"X-Movie-Tracker-Signature": "sha256=" + hex.EncodeToString(mac.Sum(nil)),
+	})
+
+	delivery.Attempts++
+	delivery.DurationMs = int(time.Since(started).Milliseconds())
+	delivery.StatusCode = nil
+	delivery.Error = ""
+	delivery.ResponseBody = responseBody
+	if statusCode != 0 {
+		delivery.StatusCode = &statusCode
+	}
+
+	switch {
+	case err == nil && statusCode >= 200 && statusCode < 300:
+		delivery.Status = models.DeliveryDelivered
+		delivery.NextAttemptAt = nil
+	default:
+		if err != nil {
+			delivery.Error = err.Error()
+		} else {
+			delivery.Error = "unexpected status " + strconv.Itoa(statusCode)
+		}
+
+		if retry && delivery.Attempts < webhookMaxAttempts {
+			next := time.Now().Add(webhookRetryDelay << (delivery.Attempts - 1))
+			delivery.NextAttemptAt = &next
+		} else {
+			delivery.Status = models.DeliveryFailed
+			delivery.NextAttemptAt = nil
+		}
+	}
+
+	db := database.GetDB()
+	return db.Model(delivery).Select("status", "attempts", "status_code", "error", "response_body", "duration_ms", "next_attempt_at").
+		Updates(delivery).Error
+}
+
+// post sends body to rawURL and returns the response status and the start
+// of the response body.
+func (s *WebhookService) post(rawURL string, body []byte, headers map[string]string) (int, string, error) {
+	req, err := http.NewRequest(http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	// Stored as text, which Postgres only accepts as valid UTF-8 without NULs
+	text := strings.ReplaceAll(strings.ToValidUTF8(string(data), ""), "\x00", "")
+
+	return resp.StatusCode, text, nil
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
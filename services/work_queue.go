@@ -0,0 +1,58 @@
+package services
+
+import (
+	"log"
+	"sync"
+)
+
+// workQueue runs jobs in the background on a fixed number of goroutines,
+// keeping slow deliveries off the request path without starting a goroutine
+// per job. Workers start with the first job.
+type workQueue struct {
+	name    string
+	workers int
+	jobs    chan func()
+	start   sync.Once
+}
+
+func newWorkQueue(name string, workers, size int) *workQueue {
+	return &workQueue{
+		name:    name,
+		workers: workers,
+		jobs:    make(chan func(), size),
+	}
+}
+
+// enqueue queues job without blocking. It reports false, and drops the job,
+// when the queue is full.
+func (q *workQueue) enqueue(job func()) bool {
+	q.start.Do(func() {
+		for i := 0; i < q.workers; i++ {
+			go q.work()
+		}
+	})
+
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		log.Printf("Background queue %q is full", q.name)
+		return false
+	}
+}
+
+func (q *workQueue) work() {
+	for job := range q.jobs {
+		q.run(job)
+	}
+}
+
+func (q *workQueue) run(job func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Background queue %q job panicked: %v", q.name, r)
+		}
+	}()
+
+	job()
+}